@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/things-go/fsm"
+	"github.com/things-go/fsm/definition"
+)
+
+var visualizeTypes = []fsm.VisualizeType{
+	fsm.Graphviz,
+	fsm.Mermaid,
+	fsm.MermaidStateDiagram,
+	fsm.MermaidFlowChart,
+	fsm.PlantUML,
+	fsm.D2,
+	fsm.SVG,
+}
+
+var tableFormats = []fsm.TableFormat{
+	fsm.Markdown,
+	fsm.CSV,
+	fsm.HTML,
+}
+
+// runValidate checks the spec, and prints each problem found.
+func runValidate(args []string, stdout io.Writer) error {
+	fs := newFlagSet("validate", "<spec>", stdout)
+	final := fs.String("final", "", "the comma separated final states, the states which can not reach them are reported")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	file := fs.Arg(0)
+	m, err := loadMachine(file, nil)
+	if err != nil {
+		var defErrs definition.Errors
+		if !errors.As(err, &defErrs) {
+			return err
+		}
+		for _, e := range defErrs {
+			fmt.Fprintf(stdout, "%s: %v\n", file, e)
+		}
+		return problemsError(len(defErrs))
+	}
+	problems := 0
+	initial := m.initial()
+	for _, state := range m.ts.UnreachableStates(initial) {
+		fmt.Fprintf(stdout, "%s: state %q is unreachable from %q\n", file, state, initial)
+		problems++
+	}
+	if finals := splitList(*final); len(finals) > 0 {
+		for _, state := range finals {
+			if !m.ts.ContainsState(state) {
+				return fmt.Errorf("%w: final state %q does not exist", errUsage, state)
+			}
+		}
+		for _, state := range m.ts.DeadStates(finals...) {
+			fmt.Fprintf(stdout, "%s: state %q can not reach the final states\n", file, state)
+			problems++
+		}
+	}
+	if problems > 0 {
+		return problemsError(problems)
+	}
+	fmt.Fprintf(stdout, "%s: ok\n", file)
+	return nil
+}
+
+// runRender outputs the diagram of the spec.
+func runRender(args []string, stdout io.Writer) error {
+	fs := newFlagSet("render", "<spec>", stdout)
+	typ := fs.String("type", string(fsm.Graphviz), "the type of the diagram, one of "+joinValues(visualizeTypes))
+	state := fs.String("state", "", "the current state to highlight, default the initial state")
+	direction := fs.String("direction", "", "the layout direction, LR or TB")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	if !containsValue(visualizeTypes, fsm.VisualizeType(*typ)) {
+		return fmt.Errorf("%w: unknown type %q", errUsage, *typ)
+	}
+	opts := make([]fsm.VisualizeOption, 0)
+	switch fsm.Direction(*direction) {
+	case "":
+	case fsm.LeftToRight, fsm.TopToBottom:
+		opts = append(opts, fsm.WithDirection(fsm.Direction(*direction)))
+	default:
+		return fmt.Errorf("%w: unknown direction %q", errUsage, *direction)
+	}
+	m, err := loadMachine(fs.Arg(0), nil)
+	if err != nil {
+		return err
+	}
+	current, err := m.state(*state)
+	if err != nil {
+		return err
+	}
+	out, err := fsm.NewFsm[string, string](current, m.ts).VisualizeWithOptions(fsm.VisualizeType(*typ), opts...)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(stdout, out)
+	return nil
+}
+
+// runTable outputs the transition table or the state × event matrix of the spec.
+func runTable(args []string, stdout io.Writer) error {
+	fs := newFlagSet("table", "<spec>", stdout)
+	format := fs.String("format", string(fsm.Markdown), "the format of the table, one of "+joinValues(tableFormats))
+	matrix := fs.Bool("matrix", false, "output the state × event matrix instead")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	if !containsValue(tableFormats, fsm.TableFormat(*format)) {
+		return fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+	m, err := loadMachine(fs.Arg(0), nil)
+	if err != nil {
+		return err
+	}
+	var out string
+	if *matrix {
+		out, err = fsm.TransitionMatrix[string, string](m.ts, fsm.TableFormat(*format))
+	} else {
+		out, err = fsm.TransitionTable[string, string](m.ts, fsm.TableFormat(*format))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(stdout, out)
+	return nil
+}
+
+// runPath outputs the shortest events from a state to another state, one path per line.
+func runPath(args []string, stdout io.Writer) error {
+	fs := newFlagSet("path", "<spec> <from> <to>", stdout)
+	all := fs.Bool("all", false, "output all the paths without visiting a state twice")
+	limit := fs.Int("limit", 10, "the max number of the paths with -all, 0 means unlimited")
+	if err := parseFlags(fs, args, 3, 3); err != nil {
+		return err
+	}
+	m, err := loadMachine(fs.Arg(0), nil)
+	if err != nil {
+		return err
+	}
+	from, to := fs.Arg(1), fs.Arg(2)
+	for _, state := range []string{from, to} {
+		if !m.ts.ContainsState(state) {
+			return fmt.Errorf("state %q does not exist", state)
+		}
+	}
+	var paths [][]string
+	if *all {
+		paths = m.ts.AllPathsTo(from, to, *limit)
+	} else if path, ok := m.ts.PathTo(from, to); ok {
+		paths = [][]string{path}
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no path from %q to %q", from, to)
+	}
+	for _, path := range paths {
+		fmt.Fprintln(stdout, strings.Join(path, " "))
+	}
+	return nil
+}
+
+// runSimulate triggers the events in order and prints each state,
+// it stops at the first event which can not be triggered.
+func runSimulate(args []string, stdout io.Writer) error {
+	fs := newFlagSet("simulate", "<spec> <event>...", stdout)
+	state := fs.String("state", "", "the state to start, default the initial state")
+	guards := fs.String("guards", "", "the comma separated guards which pass, the others do not")
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+	passed := splitList(*guards)
+	m, err := loadMachine(fs.Arg(0), func(guard string) bool { return contains(passed, guard) })
+	if err != nil {
+		return err
+	}
+	current, err := m.state(*state)
+	if err != nil {
+		return err
+	}
+	f := fsm.NewFsm[string, string](current, m.ts)
+	fmt.Fprintln(stdout, f.Current())
+	for _, event := range fs.Args()[1:] {
+		from := f.Current()
+		if err := f.Trigger(event); err != nil {
+			return fmt.Errorf("event %q in state %q: %w", event, from, err)
+		}
+		fmt.Fprintf(stdout, "%s: %s -> %s\n", event, from, f.Current())
+	}
+	return nil
+}
+
+func problemsError(n int) error {
+	if n == 1 {
+		return errors.New("1 problem found")
+	}
+	return fmt.Errorf("%d problems found", n)
+}
+
+func joinValues[T ~string](values []T) string {
+	ss := make([]string, 0, len(values))
+	for _, v := range values {
+		ss = append(ss, string(v))
+	}
+	return strings.Join(ss, ", ")
+}
+
+func containsValue[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
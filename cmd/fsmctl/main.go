@@ -0,0 +1,188 @@
+// Command fsmctl checks, renders and runs the machine spec file,
+// which is the YAML or JSON definition parsed by definition.Parse.
+//
+// Usage:
+//
+//	fsmctl validate [-final <state>,...] <spec>
+//	fsmctl render [-type <type>] [-state <state>] <spec>
+//	fsmctl table [-format <format>] [-matrix] <spec>
+//	fsmctl path [-all] [-limit <n>] <spec> <from> <to>
+//	fsmctl simulate [-state <state>] [-guards <guard>,...] <spec> <event>...
+//	fsmctl repl [-state <state>] [-guards <guard>,...] [-render <type>] [-output <file>] <spec>
+//
+// It exits with 1 if the spec is invalid, there is no path or the simulation failed,
+// and with 2 if the usage is wrong.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/things-go/fsm"
+	"github.com/things-go/fsm/definition"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+const usage = `Usage: fsmctl <command> [flags] <spec> [args]
+
+Commands:
+  validate  check the spec for conflicts, unreachable and dead states
+  render    render the diagram of the spec
+  table     output the transition table of the spec
+  path      output the events from a state to another state
+  simulate  trigger the events in order and print each state
+  repl      explore the spec interactively
+
+Run 'fsmctl <command> -h' for the flags of the command.
+`
+
+var errUsage = errors.New("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	commands := map[string]func(args []string, stdout io.Writer) error{
+		"validate": runValidate,
+		"render":   runRender,
+		"table":    runTable,
+		"path":     runPath,
+		"simulate": runSimulate,
+		"repl": func(args []string, stdout io.Writer) error {
+			return runREPL(args, stdin, stdout)
+		},
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "fsmctl: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+	err := command(args[1:], stdout)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "fsmctl %s: %v\n", args[0], err)
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "fsmctl %s: %v\n", args[0], err)
+		return exitFailure
+	}
+}
+
+// newFlagSet returns the flag set of the command, which outputs the usage to stdout.
+func newFlagSet(name, args string, stdout io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: fsmctl %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses the flags and checks the count of the arguments is in [min, max], max < 0 means unlimited.
+func parseFlags(fs *flag.FlagSet, args []string, min, max int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() < min || (max >= 0 && fs.NArg() > max) {
+		fs.Usage()
+		return fmt.Errorf("%w: wrong number of arguments", errUsage)
+	}
+	return nil
+}
+
+// machine is the loaded spec.
+type machine struct {
+	def *definition.Definition
+	ts  *fsm.Transition[string, string]
+}
+
+// loadMachine loads the spec file, the guards are evaluated by the pass function with the guard name,
+// all the guards do not pass if it is nil.
+func loadMachine(file string, pass func(guard string) bool) (*machine, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	def, err := definition.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	guards := make(map[string]fsm.Guard[string, string])
+	for _, t := range def.Transforms {
+		if name := t.Guard; name != "" {
+			guards[name] = func(*fsm.Event[string, string]) bool { return pass != nil && pass(name) }
+		}
+	}
+	ts, err := def.Build(guards)
+	if err != nil {
+		return nil, err
+	}
+	return &machine{def: def, ts: ts}, nil
+}
+
+// initial returns the initial state of the spec, which is the first state if it is not given.
+func (m *machine) initial() string {
+	if m.def.Initial != "" {
+		return m.def.Initial
+	}
+	if len(m.def.States) > 0 {
+		return m.def.States[0].State
+	}
+	if len(m.def.Transforms) > 0 && len(m.def.Transforms[0].Src) > 0 {
+		return m.def.Transforms[0].Src[0]
+	}
+	return ""
+}
+
+// state returns the state if it is given, otherwise the initial state, the state must belong to the spec.
+func (m *machine) state(state string) (string, error) {
+	if state == "" {
+		state = m.initial()
+	}
+	if !m.ts.ContainsState(state) {
+		return "", fmt.Errorf("state %q does not exist", state)
+	}
+	return state, nil
+}
+
+// splitList splits the comma separated list.
+func splitList(s string) []string {
+	values := make([]string, 0)
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
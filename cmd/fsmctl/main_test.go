@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testOrderSpec = `
+name: order
+initial: draft
+transforms:
+  - event: submit
+    src: [draft]
+    dst: review
+  - event: approve
+    src: [review]
+    dst: published
+    guard: small
+  - event: approve
+    src: [review]
+    dst: escalated
+  - event: reject
+    src: [review, escalated]
+    dst: draft
+`
+
+func writeTestSpec(t *testing.T, spec string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "order.yaml")
+	if err := os.WriteFile(file, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func Test_Run(t *testing.T) {
+	spec := writeTestSpec(t, testOrderSpec)
+	invalid := writeTestSpec(t, testOrderSpec+`
+  - event: revive
+    src: [archived]
+    dst: draft
+  - event: submit
+    src: [draft]
+    dst: published
+`)
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		wantCode int
+		wantOut  string
+		wantErr  string
+	}{
+		{
+			name:     "no command",
+			args:     nil,
+			wantCode: exitUsage,
+			wantErr:  "Usage: fsmctl",
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"lint", spec},
+			wantCode: exitUsage,
+			wantErr:  `unknown command "lint"`,
+		},
+		{
+			name:     "validate",
+			args:     []string{"validate", "-final", "published", spec},
+			wantCode: exitOK,
+			wantOut:  spec + ": ok\n",
+		},
+		{
+			name:     "validate invalid",
+			args:     []string{"validate", invalid},
+			wantCode: exitFailure,
+			wantOut:  invalid + ": line 22: transforms #0 and #5 of event submit from state draft conflict with destination states review and published\n",
+			wantErr:  "1 problem found",
+		},
+		{
+			name:     "validate missing spec",
+			args:     []string{"validate"},
+			wantCode: exitUsage,
+			wantErr:  "wrong number of arguments",
+		},
+		{
+			name:     "render",
+			args:     []string{"render", "-type", "mermaid", "-state", "review", spec},
+			wantCode: exitOK,
+			wantOut: `---
+title: order
+---
+stateDiagram-v2
+    state "draft" as s_draft
+    state "escalated" as s_escalated
+    state "published" as s_published
+    state "review" as s_review
+    [*] --> s_review
+    s_draft --> s_review: submit
+    s_escalated --> s_draft: reject
+    s_review --> s_published: approve [small]
+    s_review --> s_escalated: approve
+    s_review --> s_draft: reject
+    classDef current fill:#00AA00
+    class s_review current
+`,
+		},
+		{
+			name:     "render unknown type",
+			args:     []string{"render", "-type", "png", spec},
+			wantCode: exitUsage,
+			wantErr:  `unknown type "png"`,
+		},
+		{
+			name:     "table",
+			args:     []string{"table", "-format", "csv", spec},
+			wantCode: exitOK,
+			wantOut: `Source,Event,Destination,Guard
+draft,submit,review,
+escalated,reject,draft,
+review,approve,published,small
+review,approve,escalated,
+review,reject,draft,
+`,
+		},
+		{
+			name:     "path",
+			args:     []string{"path", spec, "draft", "escalated"},
+			wantCode: exitOK,
+			wantOut:  "submit approve\n",
+		},
+		{
+			name:     "path all",
+			args:     []string{"path", "-all", spec, "escalated", "review"},
+			wantCode: exitOK,
+			wantOut:  "reject submit\n",
+		},
+		{
+			name:     "path not found",
+			args:     []string{"path", spec, "published", "draft"},
+			wantCode: exitFailure,
+			wantErr:  `no path from "published" to "draft"`,
+		},
+		{
+			name:     "simulate",
+			args:     []string{"simulate", "-guards", "small", spec, "submit", "approve"},
+			wantCode: exitOK,
+			wantOut:  "draft\nsubmit: draft -> review\napprove: review -> published\n",
+		},
+		{
+			name:     "simulate inappropriate event",
+			args:     []string{"simulate", spec, "submit", "approve", "submit"},
+			wantCode: exitFailure,
+			wantOut:  "draft\nsubmit: draft -> review\napprove: review -> escalated\n",
+			wantErr:  `event "submit" in state "escalated": fsm: event inappropriate in the state`,
+		},
+		{
+			name:     "simulate non-exist event",
+			args:     []string{"simulate", spec, "fly"},
+			wantCode: exitFailure,
+			wantOut:  "draft\n",
+			wantErr:  `event "fly" in state "draft": fsm: event does not exist`,
+		},
+		{
+			name: "repl",
+			args: []string{"repl", spec},
+			stdin: `events
+fire submit
+events
+fire approve
+undo
+guard small on
+fire approve
+jump review
+undo
+guards
+fire fly
+quit
+`,
+			wantCode: exitOK,
+			wantOut: `draft> submit
+draft> submit: draft -> review
+review> approve
+reject
+review> approve: review -> escalated
+escalated> undo: escalated -> review
+review> review> approve: review -> published
+published> jump: published -> review
+review> error: fsm: nothing to undo
+review> small: on
+review> error: event "fly" in state "review": fsm: event does not exist
+review> `,
+		},
+		{
+			name:     "repl render",
+			args:     []string{"repl", "-render", "mermaid-state-diagram", "-state", "review", spec},
+			stdin:    "fire reject\n",
+			wantCode: exitOK,
+			wantOut: `---
+title: order
+---
+stateDiagram-v2
+    state "draft" as s_draft
+    state "escalated" as s_escalated
+    state "published" as s_published
+    state "review" as s_review
+    [*] --> s_review
+    s_draft --> s_review: submit
+    s_escalated --> s_draft: reject
+    s_review --> s_published: approve [small]
+    s_review --> s_escalated: approve
+    s_review --> s_draft: reject
+    classDef current fill:#00AA00
+    class s_review current
+review> reject: review -> draft
+---
+title: order
+---
+stateDiagram-v2
+    state "draft" as s_draft
+    state "escalated" as s_escalated
+    state "published" as s_published
+    state "review" as s_review
+    [*] --> s_draft
+    s_draft --> s_review: submit
+    s_escalated --> s_draft: reject
+    s_review --> s_published: approve [small]
+    s_review --> s_escalated: approve
+    s_review --> s_draft: reject
+    classDef current fill:#00AA00
+    class s_draft current
+draft> 
+`,
+		},
+		{
+			name:     "repl unknown render type",
+			args:     []string{"repl", "-render", "png", spec},
+			wantCode: exitUsage,
+			wantErr:  `unknown type "png"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, wanted %d, stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if tt.wantOut != "" && stdout.String() != tt.wantOut {
+				t.Errorf("stdout = \n%s\nwanted \n%s", stdout.String(), tt.wantOut)
+			}
+			if tt.wantErr != "" && !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("stderr = %q, wanted to contain %q", stderr.String(), tt.wantErr)
+			}
+		})
+	}
+}
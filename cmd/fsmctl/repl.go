@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/things-go/fsm"
+)
+
+const replHelp = `Commands:
+  current               print the current state
+  events                list the events available in the current state
+  fire <event> [arg]... trigger the event with the arguments
+  undo                  undo the last fire with the callbacks, a jump can not be undone
+  jump <state>          set the current state without any transition, it clears the fires to undo
+  guard <guard> on|off  make the guard pass or not
+  guards                list the guards and whether they pass
+  render [type]         render the diagram, default the type of -render or graphviz
+  help                  print this help
+  quit                  exit
+`
+
+// repl is the interactive session of a machine.
+type repl struct {
+	m      *machine
+	f      fsm.IFsm[string, string]
+	passed map[string]bool
+	render fsm.VisualizeType
+	output string
+	stdout io.Writer
+}
+
+// runREPL reads the commands line by line from stdin, and prints the results and the prompt to stdout.
+func runREPL(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("repl", "<spec>", stdout)
+	state := fs.String("state", "", "the state to start, default the initial state")
+	guards := fs.String("guards", "", "the comma separated guards which pass, the others do not")
+	render := fs.String("render", "", "re-render the diagram in the type after each step, one of "+joinValues(visualizeTypes))
+	output := fs.String("output", "", "write the re-rendered diagram to the file instead of stdout")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	if *render != "" && !containsValue(visualizeTypes, fsm.VisualizeType(*render)) {
+		return fmt.Errorf("%w: unknown type %q", errUsage, *render)
+	}
+	if *output != "" && *render == "" {
+		return fmt.Errorf("%w: -output requires -render", errUsage)
+	}
+
+	r := &repl{
+		passed: make(map[string]bool),
+		render: fsm.VisualizeType(*render),
+		output: *output,
+		stdout: stdout,
+	}
+	for _, guard := range splitList(*guards) {
+		r.passed[guard] = true
+	}
+	m, err := loadMachine(fs.Arg(0), func(guard string) bool { return r.passed[guard] })
+	if err != nil {
+		return err
+	}
+	current, err := m.state(*state)
+	if err != nil {
+		return err
+	}
+	r.m = m
+	r.f = fsm.NewFsm[string, string](current, m.ts, fsm.WithUndo(0))
+	if err := r.rerender(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdin)
+	for {
+		fmt.Fprintf(stdout, "%s> ", r.f.Current())
+		if !scanner.Scan() {
+			fmt.Fprintln(stdout)
+			return scanner.Err()
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" || fields[0] == "exit" {
+			return nil
+		}
+		if err := r.exec(fields[0], fields[1:]); err != nil {
+			fmt.Fprintf(stdout, "error: %v\n", err)
+		}
+	}
+}
+
+// exec executes the command, the error is reported to the user and the session goes on.
+func (r *repl) exec(command string, args []string) error {
+	switch command {
+	case "help", "?":
+		fmt.Fprint(r.stdout, replHelp)
+	case "current":
+		fmt.Fprintln(r.stdout, r.f.Current())
+	case "events":
+		events := r.f.CurrentAvailEvents()
+		sort.Strings(events)
+		for _, event := range events {
+			fmt.Fprintln(r.stdout, event)
+		}
+	case "fire":
+		if len(args) == 0 {
+			return errors.New("usage: fire <event> [arg]...")
+		}
+		from := r.f.Current()
+		eventArgs := make([]any, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			eventArgs = append(eventArgs, arg)
+		}
+		if err := r.f.Trigger(args[0], eventArgs...); err != nil {
+			return fmt.Errorf("event %q in state %q: %w", args[0], from, err)
+		}
+		fmt.Fprintf(r.stdout, "%s: %s -> %s\n", args[0], from, r.f.Current())
+		return r.rerender()
+	case "undo":
+		from := r.f.Current()
+		if err := r.f.Undo(); err != nil {
+			return err
+		}
+		fmt.Fprintf(r.stdout, "undo: %s -> %s\n", from, r.f.Current())
+		return r.rerender()
+	case "jump":
+		if len(args) != 1 {
+			return errors.New("usage: jump <state>")
+		}
+		if !r.m.ts.ContainsState(args[0]) {
+			return fmt.Errorf("state %q does not exist", args[0])
+		}
+		from := r.f.Current()
+		r.f.SetCurrent(args[0])
+		fmt.Fprintf(r.stdout, "jump: %s -> %s\n", from, args[0])
+		return r.rerender()
+	case "guard":
+		if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+			return errors.New("usage: guard <guard> on|off")
+		}
+		if !contains(r.guards(), args[0]) {
+			return fmt.Errorf("guard %q does not exist", args[0])
+		}
+		r.passed[args[0]] = args[1] == "on"
+	case "guards":
+		for _, guard := range r.guards() {
+			pass := "off"
+			if r.passed[guard] {
+				pass = "on"
+			}
+			fmt.Fprintf(r.stdout, "%s: %s\n", guard, pass)
+		}
+	case "render":
+		if len(args) > 1 {
+			return errors.New("usage: render [type]")
+		}
+		typ := r.render
+		if len(args) == 1 {
+			typ = fsm.VisualizeType(args[0])
+		}
+		if typ == "" {
+			typ = fsm.Graphviz
+		}
+		if !containsValue(visualizeTypes, typ) {
+			return fmt.Errorf("unknown type %q", typ)
+		}
+		out, err := r.f.Visualize(typ)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(r.stdout, out)
+	default:
+		return fmt.Errorf("unknown command %q, type help for the commands", command)
+	}
+	return nil
+}
+
+// rerender renders the diagram to the output file or stdout if -render is given.
+func (r *repl) rerender() error {
+	if r.render == "" {
+		return nil
+	}
+	out, err := r.f.Visualize(r.render)
+	if err != nil {
+		return err
+	}
+	if r.output != "" {
+		return os.WriteFile(r.output, []byte(out), 0o644)
+	}
+	fmt.Fprint(r.stdout, out)
+	return nil
+}
+
+// guards returns the sorted guard names of the spec.
+func (r *repl) guards() []string {
+	guards := make([]string, 0)
+	for _, t := range r.m.def.Transforms {
+		if t.Guard != "" && !contains(guards, t.Guard) {
+			guards = append(guards, t.Guard)
+		}
+	}
+	sort.Strings(guards)
+	return guards
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"reflect"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/things-go/fsm"
+	"github.com/things-go/fsm/definition"
+)
+
+type config struct {
+	// Spec is the file name of the spec in the header of the generated code.
+	Spec string
+	// Package is the package name of the generated code.
+	Package string
+	// Type is the prefix of the generated types, default the name of the definition.
+	Type string
+}
+
+type genConst struct {
+	Ident  string // the constant identifier
+	Value  string // the value in the spec
+	Name   string // the display name, may be empty
+	Method string // the event method of the machine
+}
+
+type genTransform struct {
+	Name       string
+	Event      string
+	Src        []string
+	Dst        string
+	Guard      string // the field of the guards
+	GuardName  string
+	Reversible bool
+}
+
+type genGuard struct {
+	Field string
+	Name  string
+}
+
+type genData struct {
+	config
+	Name       string
+	EventType  string
+	StateType  string
+	Initial    string
+	Events     []genConst
+	States     []genConst
+	Transforms []genTransform
+	Guards     []genGuard
+}
+
+// reservedMethods are the methods of fsm.IFsm and fsm.Subscriber, and the embedded field IFsm of the machine,
+// which the event methods must not shadow.
+var reservedMethods = func() map[string]bool {
+	methods := map[string]bool{"IFsm": true}
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf((*fsm.IFsm[string, string])(nil)).Elem(),
+		reflect.TypeOf((*fsm.Subscriber[string, string])(nil)).Elem(),
+	} {
+		for i := 0; i < typ.NumMethod(); i++ {
+			methods[typ.Method(i).Name] = true
+		}
+	}
+	return methods
+}()
+
+// generate generates the source of the definition.
+func generate(def *definition.Definition, cfg config) ([]byte, error) {
+	// validate the definition, the guards are given by the generated code.
+	guards := make(map[string]fsm.Guard[string, string])
+	for _, t := range def.Transforms {
+		if t.Guard != "" {
+			guards[t.Guard] = func(*fsm.Event[string, string]) bool { return true }
+		}
+	}
+	if _, err := def.Builder(guards); err != nil {
+		return nil, err
+	}
+
+	data := genData{config: cfg, Name: def.Name}
+	if data.Type == "" {
+		data.Type = identifier(def.Name)
+	}
+	if data.Type == "" {
+		data.Type = "Fsm"
+	}
+	if !token.IsIdentifier(data.Type) {
+		return nil, fmt.Errorf("invalid type name %q", data.Type)
+	}
+	data.EventType = data.Type + "Event"
+	data.StateType = data.Type + "State"
+
+	eventNames := make(map[string]string)
+	for _, e := range def.Events {
+		eventNames[e.Event] = e.Name
+	}
+	stateNames := make(map[string]string)
+	for _, s := range def.States {
+		stateNames[s.State] = s.Name
+	}
+	// the declared ones first, then the ones used by the transforms in order.
+	events := make([]string, 0, len(def.Events))
+	for _, e := range def.Events {
+		events = append(events, e.Event)
+	}
+	states := make([]string, 0, len(def.States))
+	for _, s := range def.States {
+		states = append(states, s.State)
+	}
+	for _, t := range def.Transforms {
+		events = appendUnique(events, t.Event)
+		for _, src := range t.Src {
+			states = appendUnique(states, src)
+		}
+		states = appendUnique(states, t.Dst)
+	}
+
+	eventsIdent := make(map[string]string)
+	eventIdents := make(map[string]string)
+	methods := make(map[string]string)
+	for _, e := range events {
+		ident := identifier(e)
+		if ident == "" {
+			return nil, fmt.Errorf("event %q has no letter or digit for the identifier", e)
+		}
+		c := genConst{
+			Ident:  data.EventType + ident,
+			Value:  e,
+			Name:   eventNames[e],
+			Method: ident,
+		}
+		if prev, ok := eventIdents[c.Ident]; ok {
+			return nil, fmt.Errorf("events %q and %q have the same identifier %s", prev, e, c.Ident)
+		}
+		if !unicode.IsUpper([]rune(c.Method)[0]) {
+			c.Method = "Event" + c.Method
+		}
+		if reservedMethods[c.Method] || reservedMethods[c.Method+"Context"] {
+			c.Method += "Event"
+		}
+		// every event has the method and its Context form.
+		for _, method := range []string{c.Method, c.Method + "Context"} {
+			if prev, ok := methods[method]; ok {
+				return nil, fmt.Errorf("events %q and %q have the same method %s", prev, e, method)
+			}
+		}
+		eventsIdent[e] = c.Ident
+		eventIdents[c.Ident] = e
+		methods[c.Method] = e
+		methods[c.Method+"Context"] = e
+		data.Events = append(data.Events, c)
+	}
+	statesIdent := make(map[string]string)
+	idents := make(map[string]string)
+	for _, s := range states {
+		ident := identifier(s)
+		if ident == "" {
+			return nil, fmt.Errorf("state %q has no letter or digit for the identifier", s)
+		}
+		c := genConst{
+			Ident: data.StateType + ident,
+			Value: s,
+			Name:  stateNames[s],
+		}
+		if prev, ok := idents[c.Ident]; ok {
+			return nil, fmt.Errorf("states %q and %q have the same identifier %s", prev, s, c.Ident)
+		}
+		if c.Ident == data.Type+"StateNames" {
+			return nil, fmt.Errorf("state %q has the same identifier as the state names %s", s, c.Ident)
+		}
+		statesIdent[s] = c.Ident
+		idents[c.Ident] = s
+		data.States = append(data.States, c)
+	}
+	if def.Initial != "" {
+		data.Initial = statesIdent[def.Initial]
+	}
+
+	guardsField := make(map[string]string)
+	fields := make(map[string]string)
+	for _, t := range def.Transforms {
+		if t.Guard == "" {
+			continue
+		}
+		if _, ok := guardsField[t.Guard]; ok {
+			continue
+		}
+		field := identifier(t.Guard)
+		if field == "" || !unicode.IsUpper([]rune(field)[0]) {
+			field = "Guard" + field
+		}
+		if prev, ok := fields[field]; ok {
+			return nil, fmt.Errorf("guards %q and %q have the same field %s", prev, t.Guard, field)
+		}
+		guardsField[t.Guard] = field
+		fields[field] = t.Guard
+		data.Guards = append(data.Guards, genGuard{Field: field, Name: t.Guard})
+	}
+	for _, t := range def.Transforms {
+		gt := genTransform{
+			Name:       eventNames[t.Event],
+			Event:      eventsIdent[t.Event],
+			Dst:        statesIdent[t.Dst],
+			Guard:      guardsField[t.Guard],
+			GuardName:  t.Guard,
+			Reversible: t.Reversible,
+		}
+		for _, src := range t.Src {
+			gt.Src = append(gt.Src, statesIdent[src])
+		}
+		data.Transforms = append(data.Transforms, gt)
+	}
+
+	var buf bytes.Buffer
+	if err := sourceTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated source: %w", err)
+	}
+	return src, nil
+}
+
+// identifier returns the exported Go identifier of the value in camel case,
+// the characters which are neither letters nor digits separate the words.
+func identifier(s string) string {
+	var b strings.Builder
+
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+var sourceTemplate = template.Must(template.New("source").Parse(`// Code generated by fsmgen from {{.Spec}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/things-go/fsm"
+)
+
+// {{.EventType}} is the event of the {{.Type}} machine.
+type {{.EventType}} int
+
+const (
+{{- range $i, $e := .Events}}
+	{{$e.Ident}}{{if eq $i 0}} {{$.EventType}} = iota + 1{{end}}
+{{- end}}
+)
+
+func (e {{.EventType}}) String() string {
+	switch e {
+{{- range .Events}}
+	case {{.Ident}}:
+		return {{printf "%q" .Value}}
+{{- end}}
+	default:
+		return "{{.EventType}}(" + strconv.Itoa(int(e)) + ")"
+	}
+}
+
+// {{.StateType}} is the state of the {{.Type}} machine.
+type {{.StateType}} int
+
+const (
+{{- range $i, $s := .States}}
+	{{$s.Ident}}{{if eq $i 0}} {{$.StateType}} = iota + 1{{end}}
+{{- end}}
+)
+
+func (s {{.StateType}}) String() string {
+	switch s {
+{{- range .States}}
+	case {{.Ident}}:
+		return {{printf "%q" .Value}}
+{{- end}}
+	default:
+		return "{{.StateType}}(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+{{- if .Initial}}
+
+// {{.Type}}Initial is the initial state of the {{.Type}} machine.
+const {{.Type}}Initial = {{.Initial}}
+{{- end}}
+
+// {{.Type}}StateNames are the display names of the states for TransitionBuilder.StateNames.
+var {{.Type}}StateNames = map[{{.StateType}}]string{
+{{- range .States}}
+	{{- if .Name}}
+	{{.Ident}}: {{printf "%q" .Name}},
+	{{- end}}
+{{- end}}
+}
+{{- if .Guards}}
+
+// {{.Type}}Guards are the guards of the {{.Type}} transforms.
+type {{.Type}}Guards struct {
+{{- range .Guards}}
+	// {{.Field}} is the guard {{printf "%q" .Name}}.
+	{{.Field}} fsm.Guard[{{$.EventType}}, {{$.StateType}}]
+{{- end}}
+}
+
+// {{.Type}}Transforms returns the transforms of the {{.Type}} machine with the guards.
+func {{.Type}}Transforms(guards {{.Type}}Guards) []fsm.Transform[{{.EventType}}, {{.StateType}}] {
+	return []fsm.Transform[{{.EventType}}, {{.StateType}}]{
+{{- range .Transforms}}
+		{ {{- if .Name}}Name: {{printf "%q" .Name}}, {{end}}Event: {{.Event}}, Src: []{{$.StateType}}{ {{- range $i, $s := .Src}}{{if $i}}, {{end}}{{$s}}{{end -}} }, Dst: {{.Dst}}
+			{{- if .Guard}}, Guard: guards.{{.Guard}}, GuardName: {{printf "%q" .GuardName}}{{end}}
+			{{- if .Reversible}}, Reversible: true{{end}}},
+{{- end}}
+	}
+}
+
+// New{{.Type}}Transition returns the transition of the {{.Type}} machine with the guards.
+func New{{.Type}}Transition(guards {{.Type}}Guards) *fsm.Transition[{{.EventType}}, {{.StateType}}] {
+	return fsm.NewTransitionBuilder({{.Type}}Transforms(guards)).
+		Name({{printf "%q" .Name}}).
+		StateNames({{.Type}}StateNames).
+		Build()
+}
+{{- else}}
+
+// {{.Type}}Transforms are the transforms of the {{.Type}} machine.
+var {{.Type}}Transforms = []fsm.Transform[{{.EventType}}, {{.StateType}}]{
+{{- range .Transforms}}
+	{ {{- if .Name}}Name: {{printf "%q" .Name}}, {{end}}Event: {{.Event}}, Src: []{{$.StateType}}{ {{- range $i, $s := .Src}}{{if $i}}, {{end}}{{$s}}{{end -}} }, Dst: {{.Dst}}{{if .Reversible}}, Reversible: true{{end}}},
+{{- end}}
+}
+
+// New{{.Type}}Transition returns the transition of the {{.Type}} machine.
+func New{{.Type}}Transition() *fsm.Transition[{{.EventType}}, {{.StateType}}] {
+	return fsm.NewTransitionBuilder({{.Type}}Transforms).
+		Name({{printf "%q" .Name}}).
+		StateNames({{.Type}}StateNames).
+		Build()
+}
+{{- end}}
+
+// {{.Type}}Fsm is the {{.Type}} machine with the methods to trigger the events.
+type {{.Type}}Fsm struct {
+	fsm.IFsm[{{.EventType}}, {{.StateType}}]
+}
+
+// New{{.Type}}Fsm returns the {{.Type}} machine in the state with the transition and the options.
+func New{{.Type}}Fsm(state {{.StateType}}, ts *fsm.Transition[{{.EventType}}, {{.StateType}}], opts ...fsm.Option) *{{.Type}}Fsm {
+	return &{{.Type}}Fsm{fsm.NewSafeFsm[{{.EventType}}, {{.StateType}}](state, ts, opts...)}
+}
+
+// Subscribe returns a channel which receives the state changes of the {{.Type}} machine
+// and the function to unsubscribe, see fsm.SafeFsm.Subscribe.
+func (f *{{.Type}}Fsm) Subscribe(opts ...fsm.SubscribeOption) (<-chan fsm.Change[{{.EventType}}, {{.StateType}}], func()) {
+	return f.IFsm.(fsm.Subscriber[{{.EventType}}, {{.StateType}}]).Subscribe(opts...)
+}
+{{- range .Events}}
+
+// {{.Method}} triggers the event {{printf "%q" .Value}}.
+func (f *{{$.Type}}Fsm) {{.Method}}(args ...any) error {
+	return f.Trigger({{.Ident}}, args...)
+}
+
+// {{.Method}}Context triggers the event {{printf "%q" .Value}} with the context.
+func (f *{{$.Type}}Fsm) {{.Method}}Context(ctx context.Context, args ...any) error {
+	return f.TriggerContext(ctx, {{.Ident}}, args...)
+}
+{{- end}}
+`))
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/things-go/fsm/definition"
+)
+
+const testLampSpec = `name: lamp
+initial: closed
+states:
+  - state: closed
+    name: Closed
+  - state: opened
+    name: Opened
+transforms:
+  - event: open
+    src: [closed]
+    dst: opened
+  - event: close
+    src: [opened]
+    dst: closed
+`
+
+func Test_Generate(t *testing.T) {
+	def, err := definition.Parse([]byte(testLampSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generate(def, config{Spec: "lamp.yaml", Package: "lamp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := `// Code generated by fsmgen from lamp.yaml. DO NOT EDIT.
+
+package lamp
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/things-go/fsm"
+)
+
+// LampEvent is the event of the Lamp machine.
+type LampEvent int
+
+const (
+	LampEventOpen LampEvent = iota + 1
+	LampEventClose
+)
+
+func (e LampEvent) String() string {
+	switch e {
+	case LampEventOpen:
+		return "open"
+	case LampEventClose:
+		return "close"
+	default:
+		return "LampEvent(" + strconv.Itoa(int(e)) + ")"
+	}
+}
+
+// LampState is the state of the Lamp machine.
+type LampState int
+
+const (
+	LampStateClosed LampState = iota + 1
+	LampStateOpened
+)
+
+func (s LampState) String() string {
+	switch s {
+	case LampStateClosed:
+		return "closed"
+	case LampStateOpened:
+		return "opened"
+	default:
+		return "LampState(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// LampInitial is the initial state of the Lamp machine.
+const LampInitial = LampStateClosed
+
+// LampStateNames are the display names of the states for TransitionBuilder.StateNames.
+var LampStateNames = map[LampState]string{
+	LampStateClosed: "Closed",
+	LampStateOpened: "Opened",
+}
+
+// LampTransforms are the transforms of the Lamp machine.
+var LampTransforms = []fsm.Transform[LampEvent, LampState]{
+	{Event: LampEventOpen, Src: []LampState{LampStateClosed}, Dst: LampStateOpened},
+	{Event: LampEventClose, Src: []LampState{LampStateOpened}, Dst: LampStateClosed},
+}
+
+// NewLampTransition returns the transition of the Lamp machine.
+func NewLampTransition() *fsm.Transition[LampEvent, LampState] {
+	return fsm.NewTransitionBuilder(LampTransforms).
+		Name("lamp").
+		StateNames(LampStateNames).
+		Build()
+}
+
+// LampFsm is the Lamp machine with the methods to trigger the events.
+type LampFsm struct {
+	fsm.IFsm[LampEvent, LampState]
+}
+
+// NewLampFsm returns the Lamp machine in the state with the transition and the options.
+func NewLampFsm(state LampState, ts *fsm.Transition[LampEvent, LampState], opts ...fsm.Option) *LampFsm {
+	return &LampFsm{fsm.NewSafeFsm[LampEvent, LampState](state, ts, opts...)}
+}
+
+// Subscribe returns a channel which receives the state changes of the Lamp machine
+// and the function to unsubscribe, see fsm.SafeFsm.Subscribe.
+func (f *LampFsm) Subscribe(opts ...fsm.SubscribeOption) (<-chan fsm.Change[LampEvent, LampState], func()) {
+	return f.IFsm.(fsm.Subscriber[LampEvent, LampState]).Subscribe(opts...)
+}
+
+// Open triggers the event "open".
+func (f *LampFsm) Open(args ...any) error {
+	return f.Trigger(LampEventOpen, args...)
+}
+
+// OpenContext triggers the event "open" with the context.
+func (f *LampFsm) OpenContext(ctx context.Context, args ...any) error {
+	return f.TriggerContext(ctx, LampEventOpen, args...)
+}
+
+// Close triggers the event "close".
+func (f *LampFsm) Close(args ...any) error {
+	return f.Trigger(LampEventClose, args...)
+}
+
+// CloseContext triggers the event "close" with the context.
+func (f *LampFsm) CloseContext(ctx context.Context, args ...any) error {
+	return f.TriggerContext(ctx, LampEventClose, args...)
+}
+`
+	if string(got) != wanted {
+		t.Errorf("generate failed. \nwanted \n%s\nand got \n%s\n", wanted, got)
+	}
+}
+
+func Test_Generate_Guard(t *testing.T) {
+	def, err := definition.Parse([]byte(`
+name: order
+transforms:
+  - event: approve
+    src: [review]
+    dst: published
+    guard: small change
+    reversible: true
+  - event: approve
+    src: [review]
+    dst: draft
+  - event: current
+    src: [draft]
+    dst: review
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generate(def, config{Spec: "order.yaml", Package: "order", Type: "Doc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"type DocGuards struct {",
+		"SmallChange fsm.Guard[DocEvent, DocState]",
+		"func DocTransforms(guards DocGuards) []fsm.Transform[DocEvent, DocState] {",
+		`Guard: guards.SmallChange, GuardName: "small change", Reversible: true}`,
+		"Dst: DocStateDraft},",
+		"func NewDocTransition(guards DocGuards) *fsm.Transition[DocEvent, DocState] {",
+		// the event method must not shadow the method of fsm.IFsm.
+		"func (f *DocFsm) CurrentEvent(args ...any) error {",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("generated source does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func Test_Generate_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		cfg  config
+	}{
+		{
+			name: "invalid definition",
+			spec: `
+transforms:
+  - event: open
+    src: []
+    dst: opened
+`,
+		},
+		{
+			name: "same identifier",
+			spec: `
+transforms:
+  - event: open
+    src: [in-review]
+    dst: in_review
+`,
+		},
+		{
+			name: "same event identifier",
+			spec: `
+transforms:
+  - event: open
+    src: [closed]
+    dst: opened
+  - event: Open
+    src: [opened]
+    dst: closed
+`,
+		},
+		{
+			name: "same method as the embedded field",
+			spec: `
+transforms:
+  - event: iFsm
+    src: [closed]
+    dst: opened
+  - event: i_fsm_event
+    src: [opened]
+    dst: closed
+`,
+		},
+		{
+			name: "event without identifier",
+			spec: `
+transforms:
+  - event: "!"
+    src: [closed]
+    dst: opened
+`,
+		},
+		{
+			name: "state without identifier",
+			spec: `
+transforms:
+  - event: open
+    src: ["--"]
+    dst: opened
+`,
+		},
+		{
+			name: "same context method",
+			spec: `
+transforms:
+  - event: open
+    src: [closed]
+    dst: opened
+  - event: open_context
+    src: [closed]
+    dst: opened
+`,
+		},
+		{
+			name: "invalid type",
+			spec: `
+transforms:
+  - event: open
+    src: [closed]
+    dst: opened
+`,
+			cfg: config{Type: "1Lamp"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := definition.Parse([]byte(tt.spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.cfg.Package == "" {
+				tt.cfg.Package = "lamp"
+			}
+			if _, err = generate(def, tt.cfg); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func Test_Generate_Build(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip building the generated source in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, err := definition.Parse([]byte(`
+name: order
+initial: draft
+transforms:
+  # an event named as the identifier of another event.
+  - event: OrderEventSubmit
+    src: [draft]
+    dst: draft
+  - event: submit
+    src: [draft]
+    dst: review
+  - event: current
+    src: [review]
+    dst: draft
+  - event: subscribe
+    src: [draft]
+    dst: draft
+  - event: iFsm
+    src: [draft]
+    dst: draft
+  - event: 2fa check
+    src: [review]
+    dst: published
+    guard: small change
+    reversible: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate(def, config{Spec: "order.yaml", Package: "order"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"go.mod":       []byte("module example.com/order\n\ngo 1.20\n\nrequire github.com/things-go/fsm v0.0.0\n\nreplace github.com/things-go/fsm => " + root + "\n"),
+		"go.sum":       goSum,
+		"order_fsm.go": src,
+	}
+	for name, data := range files {
+		if err = os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"build", "./..."}, {"vet", "./..."}} {
+		cmd := exec.Command(goBin, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("go %s failed %v:\n%s\n%s", strings.Join(args, " "), err, out, src)
+		}
+	}
+}
+
+func Test_Run(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "lamp.yaml")
+	if err := os.WriteFile(spec, []byte(testLampSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "lamp_fsm.go")
+	if err := run(spec, output, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), "// Code generated by fsmgen from lamp.yaml. DO NOT EDIT.\n\npackage main\n") {
+		t.Errorf("unexpected generated source:\n%s", got)
+	}
+
+	err = run(filepath.Join(dir, "none.yaml"), output, "", "")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected error %v, got %v", os.ErrNotExist, err)
+	}
+}
+
+func Test_Identifier(t *testing.T) {
+	tests := []struct {
+		value  string
+		wanted string
+	}{
+		{"open", "Open"},
+		{"partial-close", "PartialClose"},
+		{"in review", "InReview"},
+		{"in_review", "InReview"},
+		{"v2 ready", "V2Ready"},
+		{"--", ""},
+	}
+	for _, tt := range tests {
+		if got := identifier(tt.value); got != tt.wanted {
+			t.Errorf("identifier(%q) = %q, wanted %q", tt.value, got, tt.wanted)
+		}
+	}
+}
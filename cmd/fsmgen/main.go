@@ -0,0 +1,73 @@
+// Command fsmgen generates the typed events, states, transforms and event methods of a machine
+// from the spec file, which is the YAML or JSON definition parsed by definition.Parse.
+//
+// It is used with go generate:
+//
+//	//go:generate go run github.com/things-go/fsm/cmd/fsmgen -spec order.yaml
+//
+// Usage:
+//
+//	fsmgen -spec <file> [-output <file>] [-package <name>] [-type <name>]
+//
+// The output defaults to <spec>_fsm.go in the current directory, the package defaults to
+// $GOPACKAGE set by go generate, and the type defaults to the name of the spec.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/things-go/fsm/definition"
+)
+
+func main() {
+	var (
+		spec    = flag.String("spec", "", "the spec file of the machine in YAML or JSON")
+		output  = flag.String("output", "", "the output file, default <spec>_fsm.go")
+		pkg     = flag.String("package", os.Getenv("GOPACKAGE"), "the package name, default $GOPACKAGE or main")
+		typName = flag.String("type", "", "the type name prefix, default the name of the spec")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: fsmgen -spec <file> [flags]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *spec == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*spec, *output, *pkg, *typName); err != nil {
+		fmt.Fprintf(os.Stderr, "fsmgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(spec, output, pkg, typName string) error {
+	data, err := os.ReadFile(spec)
+	if err != nil {
+		return err
+	}
+	def, err := definition.Parse(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", spec, err)
+	}
+	if pkg == "" {
+		pkg = "main"
+	}
+	src, err := generate(def, config{
+		Spec:    filepath.Base(spec),
+		Package: pkg,
+		Type:    typName,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", spec, err)
+	}
+	if output == "" {
+		base := filepath.Base(spec)
+		output = strings.TrimSuffix(base, filepath.Ext(base)) + "_fsm.go"
+	}
+	return os.WriteFile(output, src, 0o644)
+}
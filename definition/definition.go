@@ -0,0 +1,395 @@
+// Package definition loads the transitions from the YAML, JSON and W3C SCXML data files,
+// and exports the transitions to them.
+package definition
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/things-go/fsm"
+	"gopkg.in/yaml.v3"
+)
+
+// Definition is the data file definition of a transition, it can be written in YAML or JSON.
+//
+//	name: order
+//	initial: draft
+//	states:
+//	  - state: draft
+//	    name: Draft
+//	events:
+//	  - event: submit
+//	    name: Submit
+//	transforms:
+//	  - event: submit
+//	    src: [draft]
+//	    dst: review
+//
+// The states and events are optional, if they are given, the transforms must use the declared ones.
+type Definition struct {
+	// Name is the name of the transition.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Initial is the initial state of the machine, it is optional.
+	Initial string `json:"initial,omitempty" yaml:"initial,omitempty"`
+	// States are the states with display names.
+	States []State `json:"states,omitempty" yaml:"states,omitempty"`
+	// Events are the events with display names.
+	Events []Event `json:"events,omitempty" yaml:"events,omitempty"`
+	// Transforms are the transforms of the transition.
+	Transforms []Transform `json:"transforms" yaml:"transforms"`
+
+	initialLine int
+}
+
+// State is a state of the Definition.
+type State struct {
+	// State is the state.
+	State string `json:"state" yaml:"state"`
+	// Name is the display name of the state.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	line int
+}
+
+// Event is an event of the Definition.
+type Event struct {
+	// Event is the event.
+	Event string `json:"event" yaml:"event"`
+	// Name is the display name of the event.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	line int
+}
+
+// Transform is a transform of the Definition.
+type Transform struct {
+	// Event is the event of the transform.
+	Event string `json:"event" yaml:"event"`
+	// Src is the source states of the transform.
+	Src []string `json:"src" yaml:"src,flow"`
+	// Dst is the destination state of the transform.
+	Dst string `json:"dst" yaml:"dst"`
+	// Guard is the guard name of the transform, it is optional.
+	Guard string `json:"guard,omitempty" yaml:"guard,omitempty"`
+	// Reversible marks the transform can be undone, it is optional.
+	Reversible bool `json:"reversible,omitempty" yaml:"reversible,omitempty"`
+
+	line int
+}
+
+// Error is a problem of the definition data at the line, the line is 0 if unknown.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Errors is the list of all problems of the definition data.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return "fsm: invalid definition: " + strings.Join(msgs, "; ")
+}
+
+var yamlErrorLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// Parse parses the definition from YAML or JSON data,
+// it returns Errors with the line numbers of the problems.
+func Parse(data []byte) (*Definition, error) {
+	var def Definition
+	var node yaml.Node
+
+	// the yaml parser reports the line of the enclosing node for the JSON syntax errors,
+	// so check the JSON syntax first to get the exact line.
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var v any
+		var syntaxErr *json.SyntaxError
+		if err := json.Unmarshal(data, &v); errors.As(err, &syntaxErr) {
+			line := bytes.Count(data[:syntaxErr.Offset], []byte("\n")) + 1
+			return nil, Errors{{line, syntaxErr.Error()}}
+		}
+	}
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, intoErrors(err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&def); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, Errors{{0, "definition is empty"}}
+		}
+		return nil, intoErrors(err)
+	}
+	if len(node.Content) > 0 {
+		root := node.Content[0]
+		def.initialLine = keyLine(root, "initial")
+		lines := sequenceLines(root, "states")
+		for i := range def.States {
+			def.States[i].line = lines[i]
+		}
+		lines = sequenceLines(root, "events")
+		for i := range def.Events {
+			def.Events[i].line = lines[i]
+		}
+		lines = sequenceLines(root, "transforms")
+		for i := range def.Transforms {
+			def.Transforms[i].line = lines[i]
+		}
+	}
+	return &def, nil
+}
+
+// LoadTransition parses the definition from YAML or JSON data and builds the transition.
+func LoadTransition(data []byte) (*fsm.Transition[string, string], error) {
+	def, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return def.Build(nil)
+}
+
+// Builder returns the TransitionBuilder of the definition, the guards are looked up by the guard names of the transforms.
+// It returns Errors if the definition is invalid.
+func (d *Definition) Builder(guards map[string]fsm.Guard[string, string]) (*fsm.TransitionBuilder[string, string], error) {
+	var errs Errors
+
+	addErr := func(line int, format string, a ...any) {
+		errs = append(errs, &Error{line, fmt.Sprintf(format, a...)})
+	}
+	states := make(map[string]string)
+	for _, s := range d.States {
+		if s.State == "" {
+			addErr(s.line, "state is empty")
+			continue
+		}
+		if _, ok := states[s.State]; ok {
+			addErr(s.line, "state %q is declared twice", s.State)
+		}
+		states[s.State] = s.Name
+	}
+	events := make(map[string]string)
+	for _, e := range d.Events {
+		if e.Event == "" {
+			addErr(e.line, "event is empty")
+			continue
+		}
+		if _, ok := events[e.Event]; ok {
+			addErr(e.line, "event %q is declared twice", e.Event)
+		}
+		events[e.Event] = e.Name
+	}
+	usedStates := make(map[string]struct{})
+	usedEvents := make(map[string]struct{})
+	checkState := func(line int, state string) {
+		usedStates[state] = struct{}{}
+		if len(d.States) > 0 && state != "" {
+			if _, ok := states[state]; !ok {
+				addErr(line, "state %q is not declared", state)
+			}
+		}
+	}
+	transforms := make([]fsm.Transform[string, string], 0, len(d.Transforms))
+	for _, t := range d.Transforms {
+		usedEvents[t.Event] = struct{}{}
+		if t.Event == "" {
+			addErr(t.line, "transform event is empty")
+		} else if _, ok := events[t.Event]; !ok && len(d.Events) > 0 {
+			addErr(t.line, "event %q is not declared", t.Event)
+		}
+		if t.Dst == "" {
+			addErr(t.line, "transform dst is empty")
+		}
+		checkState(t.line, t.Dst)
+		for _, src := range t.Src {
+			checkState(t.line, src)
+		}
+		var guard fsm.Guard[string, string]
+		if t.Guard != "" {
+			var ok bool
+			if guard, ok = guards[t.Guard]; !ok {
+				addErr(t.line, "guard %q is not given", t.Guard)
+			}
+		}
+		transforms = append(transforms, fsm.Transform[string, string]{
+			Name:       events[t.Event],
+			Event:      t.Event,
+			Src:        t.Src,
+			Dst:        t.Dst,
+			Guard:      guard,
+			GuardName:  t.Guard,
+			Reversible: t.Reversible,
+		})
+	}
+	if d.Initial != "" {
+		if _, ok := usedStates[d.Initial]; !ok {
+			addErr(d.initialLine, "initial state %q is not used by any transform", d.Initial)
+		}
+	}
+	for _, s := range d.States {
+		if _, ok := usedStates[s.State]; !ok && s.State != "" {
+			addErr(s.line, "state %q is declared but not used", s.State)
+		}
+	}
+	for _, e := range d.Events {
+		if _, ok := usedEvents[e.Event]; !ok && e.Event != "" {
+			addErr(e.line, "event %q is declared but not used", e.Event)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	b := fsm.NewTransitionBuilder(transforms).
+		Name(d.Name).
+		StateNames(states)
+	var validateErrs fsm.ValidateErrors
+	if err := b.Validate(); errors.As(err, &validateErrs) {
+		for _, e := range validateErrs {
+			line := 0
+			if len(e.Transforms) > 0 {
+				line = d.Transforms[e.Transforms[len(e.Transforms)-1]].line
+			}
+			addErr(line, "%s", e.Msg)
+		}
+		return nil, errs
+	}
+	return b, nil
+}
+
+// Build builds the transition of the definition, the guards are looked up by the guard names of the transforms.
+// It returns Errors if the definition is invalid.
+func (d *Definition) Build(guards map[string]fsm.Guard[string, string]) (*fsm.Transition[string, string], error) {
+	b, err := d.Builder(guards)
+	if err != nil {
+		return nil, err
+	}
+	return b.Build(), nil
+}
+
+// ToJSON returns the definition in JSON.
+func (d *Definition) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// ToYAML returns the definition in YAML.
+func (d *Definition) ToYAML() ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(d); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Export exports the transition to the definition, the initial state is optional.
+// The edges with the same event, destination state, guard and reversibility are merged into one transform.
+func Export(ts *fsm.Transition[string, string], initState string) *Definition {
+	def := &Definition{
+		Name:       ts.Name(),
+		Initial:    initState,
+		States:     make([]State, 0),
+		Events:     make([]Event, 0),
+		Transforms: make([]Transform, 0),
+	}
+	for _, state := range ts.SortedStates() {
+		s := State{State: state}
+		if name := ts.StateName(state); name != state {
+			s.Name = name
+		}
+		def.States = append(def.States, s)
+	}
+	for _, event := range ts.SortedEvents() {
+		e := Event{Event: event}
+		if name := ts.EventName(event); name != event {
+			e.Name = name
+		}
+		def.Events = append(def.Events, e)
+	}
+	merged := make(map[fsm.Edge[string, string]]int)
+	for _, edge := range ts.SortedEdges() {
+		key := fsm.Edge[string, string]{Event: edge.Event, Dst: edge.Dst, Guard: edge.Guard, Reversible: edge.Reversible}
+		if i, ok := merged[key]; ok {
+			def.Transforms[i].Src = append(def.Transforms[i].Src, edge.Src)
+			continue
+		}
+		merged[key] = len(def.Transforms)
+		def.Transforms = append(def.Transforms, Transform{
+			Event:      edge.Event,
+			Src:        []string{edge.Src},
+			Dst:        edge.Dst,
+			Guard:      edge.Guard,
+			Reversible: edge.Reversible,
+		})
+	}
+	return def
+}
+
+// keyLine returns the line of the key in the mapping node, 0 if not found.
+func keyLine(mapping *yaml.Node, key string) int {
+	if mapping.Kind != yaml.MappingNode {
+		return 0
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i].Line
+		}
+	}
+	return 0
+}
+
+// sequenceLines returns the lines of the items of the sequence with the key in the mapping node.
+func sequenceLines(mapping *yaml.Node, key string) []int {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key && mapping.Content[i+1].Kind == yaml.SequenceNode {
+			lines := make([]int, 0, len(mapping.Content[i+1].Content))
+			for _, item := range mapping.Content[i+1].Content {
+				lines = append(lines, item.Line)
+			}
+			return lines
+		}
+	}
+	return nil
+}
+
+// intoErrors converts the yaml errors into Errors with the line numbers.
+func intoErrors(err error) error {
+	var msgs []string
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msgs = typeErr.Errors
+	} else {
+		msgs = []string{err.Error()}
+	}
+	errs := make(Errors, 0, len(msgs))
+	for _, msg := range msgs {
+		if m := yamlErrorLine.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			errs = append(errs, &Error{line, m[2]})
+		} else {
+			errs = append(errs, &Error{0, strings.TrimPrefix(msg, "yaml: ")})
+		}
+	}
+	return errs
+}
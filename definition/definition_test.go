@@ -0,0 +1,195 @@
+package definition
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/things-go/fsm"
+)
+
+const testDefinitionYAML = `name: order
+initial: draft
+states:
+  - state: draft
+    name: Draft
+  - state: review
+    name: In Review
+  - state: published
+events:
+  - event: submit
+    name: Submit
+  - event: approve
+  - event: reject
+transforms:
+  - event: submit
+    src: [draft]
+    dst: review
+    reversible: true
+  - event: approve
+    src: [review]
+    dst: published
+  - event: reject
+    src: [review, published]
+    dst: draft
+`
+
+const testDefinitionJSON = `{
+  "name": "order",
+  "transforms": [
+    {"event": "submit", "src": ["draft"], "dst": "review", "reversible": true},
+    {"event": "approve", "src": ["review"], "dst": "published"},
+    {"event": "reject", "src": ["review", "published"], "dst": "draft"}
+  ]
+}`
+
+func Test_LoadTransition(t *testing.T) {
+	for _, data := range []string{testDefinitionYAML, testDefinitionJSON} {
+		ts, err := LoadTransition([]byte(data))
+		if err != nil {
+			t.Fatalf("load failed %v", err)
+		}
+		if ts.Name() != "order" {
+			t.Errorf("expected name 'order', but got %s", ts.Name())
+		}
+		wanted := []fsm.Edge[string, string]{
+			{Event: "submit", Src: "draft", Dst: "review", Reversible: true},
+			{Event: "reject", Src: "published", Dst: "draft"},
+			{Event: "approve", Src: "review", Dst: "published"},
+			{Event: "reject", Src: "review", Dst: "draft"},
+		}
+		if got := ts.SortedEdges(); !reflect.DeepEqual(got, wanted) {
+			t.Errorf("expected edges %v, but got %v", wanted, got)
+		}
+	}
+	ts, _ := LoadTransition([]byte(testDefinitionYAML))
+	if ts.StateName("review") != "In Review" || ts.EventName("submit") != "Submit" {
+		t.Errorf("expected state and event names, but got %s and %s", ts.StateName("review"), ts.EventName("submit"))
+	}
+}
+
+func Test_LoadTransition_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		lines []int
+	}{
+		{
+			name:  "syntax",
+			data:  "name: order\ntransforms:\n\t- event: submit\n",
+			lines: []int{3},
+		},
+		{
+			name:  "json syntax",
+			data:  "{\n  \"name\": \"order\",\n  \"transforms\": [\n    {\"event\": \"a\"} {\"event\": \"b\"}\n  ]\n}\n",
+			lines: []int{4},
+		},
+		{
+			name:  "unknown field",
+			data:  "name: order\ntransforms:\n  - event: submit\n    source: [draft]\n    dst: review\n",
+			lines: []int{4},
+		},
+		{
+			name: "semantic",
+			data: `name: order
+initial: unknown
+states:
+  - state: draft
+  - state: review
+  - state: orphan
+events:
+  - event: submit
+transforms:
+  - event: submit
+    src: [draft]
+    dst: review
+  - event: approve
+    src: []
+    dst: published
+  - event: submit
+    src: [draft]
+    dst: draft
+`,
+			lines: []int{13, 13, 2, 6},
+		},
+		{
+			name:  "conflict",
+			data:  "transforms:\n  - event: submit\n    src: [draft]\n    dst: review\n  - event: submit\n    src: [draft]\n    dst: draft\n",
+			lines: []int{5},
+		},
+		{
+			name:  "empty",
+			data:  "",
+			lines: []int{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTransition([]byte(tt.data))
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected 'Errors', but got %v", err)
+			}
+			lines := make([]int, 0, len(errs))
+			for _, e := range errs {
+				lines = append(lines, e.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.lines) {
+				t.Errorf("expected error lines %v, but got %v: %v", tt.lines, lines, err)
+			}
+		})
+	}
+}
+
+func Test_Export(t *testing.T) {
+	ts, err := LoadTransition([]byte(testDefinitionYAML))
+	if err != nil {
+		t.Fatalf("load failed %v", err)
+	}
+	def := Export(ts, "draft")
+	for _, encode := range []func() ([]byte, error){def.ToYAML, def.ToJSON} {
+		data, err := encode()
+		if err != nil {
+			t.Fatalf("encode failed %v", err)
+		}
+		got, err := LoadTransition(data)
+		if err != nil {
+			t.Fatalf("load exported definition failed %v\n%s", err, data)
+		}
+		if !reflect.DeepEqual(got.SortedEdges(), ts.SortedEdges()) {
+			t.Errorf("expected edges %v, but got %v", ts.SortedEdges(), got.SortedEdges())
+		}
+		if got.StateName("review") != "In Review" || got.EventName("submit") != "Submit" {
+			t.Errorf("expected state and event names exported\n%s", data)
+		}
+	}
+	data, _ := def.ToYAML()
+	wanted := `name: order
+initial: draft
+states:
+  - state: draft
+    name: Draft
+  - state: published
+  - state: review
+    name: In Review
+events:
+  - event: approve
+  - event: reject
+  - event: submit
+    name: Submit
+transforms:
+  - event: submit
+    src: [draft]
+    dst: review
+    reversible: true
+  - event: reject
+    src: [published, review]
+    dst: draft
+  - event: approve
+    src: [review]
+    dst: published
+`
+	if string(data) != wanted {
+		t.Errorf("expected yaml \n%s\nbut got \n%s", wanted, data)
+	}
+}
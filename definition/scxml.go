@@ -0,0 +1,173 @@
+package definition
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// SCXMLNamespace is the namespace of the W3C SCXML.
+	SCXMLNamespace = "http://www.w3.org/2005/07/scxml"
+	// SCXMLLabelNamespace is the namespace of the label attribute, which carries the names of the states and events.
+	SCXMLLabelNamespace = "https://github.com/things-go/fsm"
+)
+
+var ErrInvalidSCXML = errors.New("fsm: invalid scxml")
+
+type scxmlDocument struct {
+	XMLName  xml.Name       `xml:"scxml"`
+	Name     string         `xml:"name,attr"`
+	Initial  string         `xml:"initial,attr"`
+	Elements []scxmlElement `xml:",any"`
+}
+
+type scxmlElement struct {
+	XMLName     xml.Name          `xml:""`
+	ID          string            `xml:"id,attr"`
+	Label       string            `xml:"https://github.com/things-go/fsm label,attr"`
+	Transitions []scxmlTransition `xml:"transition"`
+	Children    []scxmlElement    `xml:",any"`
+}
+
+type scxmlTransition struct {
+	Event  string `xml:"event,attr"`
+	Target string `xml:"target,attr"`
+	Cond   string `xml:"cond,attr"`
+	Label  string `xml:"https://github.com/things-go/fsm label,attr"`
+}
+
+// ParseSCXML parses the definition from the W3C SCXML data.
+// Only the flat state charts are supported, the states, the transitions with event and target,
+// the initial state and the labels are imported, the cond of the transition is imported as the guard name.
+// The initial state is the first state if the initial attribute is not given.
+func ParseSCXML(data []byte) (*Definition, error) {
+	var doc scxmlDocument
+
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSCXML, err)
+	}
+	if doc.XMLName.Space != SCXMLNamespace {
+		return nil, fmt.Errorf("%w: unexpected namespace %q", ErrInvalidSCXML, doc.XMLName.Space)
+	}
+	def := &Definition{
+		Name:       doc.Name,
+		Initial:    doc.Initial,
+		States:     make([]State, 0),
+		Events:     make([]Event, 0),
+		Transforms: make([]Transform, 0),
+	}
+	events := make(map[string]int)
+	for _, el := range doc.Elements {
+		switch el.XMLName.Local {
+		case "state", "final":
+		case "parallel":
+			return nil, fmt.Errorf("%w: parallel state %q is not supported", ErrInvalidSCXML, el.ID)
+		default:
+			continue
+		}
+		if el.ID == "" {
+			return nil, fmt.Errorf("%w: %s without id", ErrInvalidSCXML, el.XMLName.Local)
+		}
+		for _, child := range el.Children {
+			switch child.XMLName.Local {
+			case "state", "parallel", "final", "initial", "history":
+				return nil, fmt.Errorf("%w: nested state of %q is not supported", ErrInvalidSCXML, el.ID)
+			}
+		}
+		def.States = append(def.States, State{State: el.ID, Name: el.Label})
+		for _, tr := range el.Transitions {
+			if tr.Event == "" {
+				return nil, fmt.Errorf("%w: transition of %q without event is not supported", ErrInvalidSCXML, el.ID)
+			}
+			targets := strings.Fields(tr.Target)
+			if len(targets) != 1 {
+				return nil, fmt.Errorf("%w: transition of %q must have one target", ErrInvalidSCXML, el.ID)
+			}
+			for _, event := range strings.Fields(tr.Event) {
+				if i, ok := events[event]; !ok {
+					events[event] = len(def.Events)
+					def.Events = append(def.Events, Event{Event: event, Name: tr.Label})
+				} else if def.Events[i].Name == "" {
+					def.Events[i].Name = tr.Label
+				}
+				def.Transforms = append(def.Transforms, Transform{
+					Event: event,
+					Src:   []string{el.ID},
+					Dst:   targets[0],
+					Guard: tr.Cond,
+				})
+			}
+		}
+	}
+	if def.Initial == "" && len(def.States) > 0 {
+		def.Initial = def.States[0].State
+	}
+	return def, nil
+}
+
+// ToSCXML returns the definition in W3C SCXML, the states without outgoing transform are exported as final states,
+// the names of the states and events are exported as the label attributes in the SCXMLLabelNamespace.
+func (d *Definition) ToSCXML() ([]byte, error) {
+	var buf strings.Builder
+
+	writeAttr := func(name, value string) {
+		if value != "" {
+			buf.WriteString(" " + name + `="`)
+			_ = xml.EscapeText(&buf, []byte(value)) // never fails on strings.Builder
+			buf.WriteString(`"`)
+		}
+	}
+	states := make([]State, 0, len(d.States))
+	states = append(states, d.States...)
+	for _, t := range d.Transforms {
+		for _, state := range append(slices.Clone(t.Src), t.Dst) {
+			if !slices.ContainsFunc(states, func(s State) bool { return s.State == state }) {
+				states = append(states, State{State: state})
+			}
+		}
+	}
+	eventNames := make(map[string]string)
+	for _, e := range d.Events {
+		eventNames[e.Event] = e.Name
+	}
+
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<scxml xmlns="` + SCXMLNamespace + `" xmlns:fsm="` + SCXMLLabelNamespace + `" version="1.0"`)
+	writeAttr("name", d.Name)
+	writeAttr("initial", d.Initial)
+	buf.WriteString(">\n")
+	for _, s := range states {
+		transforms := make([]Transform, 0)
+		for _, t := range d.Transforms {
+			if slices.Contains(t.Src, s.State) {
+				transforms = append(transforms, t)
+			}
+		}
+		if len(transforms) == 0 {
+			buf.WriteString("  <final")
+			writeAttr("id", s.State)
+			writeAttr("fsm:label", s.Name)
+			buf.WriteString("/>\n")
+			continue
+		}
+		buf.WriteString("  <state")
+		writeAttr("id", s.State)
+		writeAttr("fsm:label", s.Name)
+		buf.WriteString(">\n")
+		for _, t := range transforms {
+			buf.WriteString("    <transition")
+			writeAttr("event", t.Event)
+			writeAttr("target", t.Dst)
+			writeAttr("cond", t.Guard)
+			writeAttr("fsm:label", eventNames[t.Event])
+			buf.WriteString("/>\n")
+		}
+		buf.WriteString("  </state>\n")
+	}
+	buf.WriteString("</scxml>\n")
+	return []byte(buf.String()), nil
+}
@@ -0,0 +1,101 @@
+package definition
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/things-go/fsm"
+)
+
+const testSCXML = `<?xml version="1.0" encoding="UTF-8"?>
+<scxml xmlns="http://www.w3.org/2005/07/scxml" xmlns:fsm="https://github.com/things-go/fsm" version="1.0" name="order" initial="draft">
+  <datamodel/>
+  <state id="draft" fsm:label="Draft">
+    <onentry/>
+    <transition event="submit" target="review" fsm:label="Submit"/>
+  </state>
+  <state id="review">
+    <transition event="approve" target="published" cond="small"/>
+    <transition event="approve reject" target="draft"/>
+  </state>
+  <final id="published" fsm:label="Published &amp; Live"/>
+</scxml>
+`
+
+func Test_ParseSCXML(t *testing.T) {
+	def, err := ParseSCXML([]byte(testSCXML))
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+	if def.Name != "order" || def.Initial != "draft" {
+		t.Errorf("expected name 'order' and initial 'draft', but got %q and %q", def.Name, def.Initial)
+	}
+	ts, err := def.Build(map[string]fsm.Guard[string, string]{
+		"small": func(e *fsm.Event[string, string]) bool { return true },
+	})
+	if err != nil {
+		t.Fatalf("build failed %v", err)
+	}
+	wanted := []fsm.Edge[string, string]{
+		{Event: "submit", Src: "draft", Dst: "review"},
+		{Event: "approve", Src: "review", Dst: "published", Guard: "small"},
+		{Event: "approve", Src: "review", Dst: "draft"},
+		{Event: "reject", Src: "review", Dst: "draft"},
+	}
+	if got := ts.SortedEdges(); !reflect.DeepEqual(got, wanted) {
+		t.Errorf("expected edges %v, but got %v", wanted, got)
+	}
+	if ts.StateName("published") != "Published & Live" || ts.EventName("submit") != "Submit" {
+		t.Errorf("expected labels as names, but got %q and %q", ts.StateName("published"), ts.EventName("submit"))
+	}
+}
+
+func Test_Definition_ToSCXML(t *testing.T) {
+	def, err := ParseSCXML([]byte(testSCXML))
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+	got, err := def.ToSCXML()
+	if err != nil {
+		t.Fatalf("export failed %v", err)
+	}
+	wanted := `<?xml version="1.0" encoding="UTF-8"?>
+<scxml xmlns="http://www.w3.org/2005/07/scxml" xmlns:fsm="https://github.com/things-go/fsm" version="1.0" name="order" initial="draft">
+  <state id="draft" fsm:label="Draft">
+    <transition event="submit" target="review" fsm:label="Submit"/>
+  </state>
+  <state id="review">
+    <transition event="approve" target="published" cond="small"/>
+    <transition event="approve" target="draft"/>
+    <transition event="reject" target="draft"/>
+  </state>
+  <final id="published" fsm:label="Published &amp; Live"/>
+</scxml>
+`
+	if string(got) != wanted {
+		t.Errorf("expected scxml \n%s\nbut got \n%s", wanted, got)
+	}
+	def2, err := ParseSCXML(got)
+	if err != nil {
+		t.Fatalf("parse exported scxml failed %v", err)
+	}
+	if !reflect.DeepEqual(def2, def) {
+		t.Errorf("expected round-trip definition %+v, but got %+v", def, def2)
+	}
+}
+
+func Test_ParseSCXML_Unsupported(t *testing.T) {
+	for _, data := range []string{
+		`<scxml xmlns="http://www.w3.org/2005/07/scxml"><state id="a"><state id="b"/></state></scxml>`,
+		`<scxml xmlns="http://www.w3.org/2005/07/scxml"><parallel id="a"/></scxml>`,
+		`<scxml xmlns="http://www.w3.org/2005/07/scxml"><state id="a"><transition target="a"/></state></scxml>`,
+		`<scxml xmlns="http://www.w3.org/2005/07/scxml"><state id="a"><transition event="e" target="a b"/></state></scxml>`,
+		`<scxml><state id="a"/></scxml>`,
+		`<scxml xmlns="http://www.w3.org/2005/07/scxml"><state id="a">`,
+	} {
+		if _, err := ParseSCXML([]byte(data)); !errors.Is(err, ErrInvalidSCXML) {
+			t.Errorf("expected 'ErrInvalidSCXML' with %s, but got %v", data, err)
+		}
+	}
+}
@@ -67,10 +67,10 @@ func main() {
 	// closed
 	fmt.Println(fsm.VisualizeGraphviz[MyEvent, MyState](f))
 	// digraph fsm {
-	//    "closed" -> "opened" [ label = "open" ];
-	//    "opened" -> "closed" [ label = "close" ];
+	//    s_closed -> s_opened [ label = "open" ];
+	//    s_opened -> s_closed [ label = "close" ];
 	//
-	//    "closed";
-	//    "opened";
+	//    s_closed [ label = "closed", style = "filled", fillcolor = "#00AA00" ];
+	//    s_opened [ label = "opened" ];
 	// }
 }
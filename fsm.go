@@ -1,6 +1,8 @@
 package fsm
 
 import (
+	"context"
+
 	"golang.org/x/exp/constraints"
 )
 
@@ -8,25 +10,67 @@ type Diagram interface {
 	// Visualize outputs a visualization of a Fsm in the desired format.
 	// If the type is not given it defaults to Graphviz
 	Visualize(t VisualizeType) (string, error)
+	// VisualizeWithOptions outputs a visualization of a Fsm in the desired format with the options.
+	// If the type is not given it defaults to Graphviz
+	VisualizeWithOptions(t VisualizeType, opts ...VisualizeOption) (string, error)
 }
 
 type IFsm[E constraints.Ordered, S constraints.Ordered] interface {
 	// Clone the Fsm.
 	Clone() IFsm[E, S]
-	// CloneNewState clone the Fsm with new state.
+	// CloneNewState clone the Fsm with new state, the history is not copied.
 	CloneNewState(newState S) IFsm[E, S]
 	// Current returns the current state.
 	Current() S
 	// Is returns true if state match the current state.
 	Is(state S) bool
-	// SetCurrent allows the user to move to the given state from current state.
+	// SetCurrent allows the user to move to the given state from current state, it is recorded in the history.
 	SetCurrent(state S)
+	// Data returns the data attached to the Fsm.
+	Data() any
+	// SetData attaches the data to the Fsm, the guards and callbacks can read and replace it by the Event.
+	// The data is copied by Clone and CloneNewState, the data which implements DataCloner
+	// is cloned by its CloneData method.
+	SetData(data any)
+	// UpdateData replaces the data attached to the Fsm with the result of fn called with the current data,
+	// the read-modify-write is atomic with the SafeFsm lock held, so fn must not call back into the same Fsm.
+	UpdateData(fn func(data any) any)
 	// Trigger call a state transition with the named event and src state if success will change the current state.
+	// The optional arguments are the payload of the event, they are passed to the guards and callbacks by the Event.
+	// The before event and leave state callbacks are called before the current state change,
+	// the enter state and after event callbacks are called after it.
 	// It will return nil if src state change to dst state success or one of these errors:
 	//
 	// - ErrInappropriateEvent: event inappropriate in the src state.
 	// - ErrNonExistEvent: event does not exist
-	Trigger(event E) error
+	// - ErrCanceled: a before event callback canceled the transform, it is wrapped in a TriggerError
+	//   with the event arguments and the callback error.
+	Trigger(event E, args ...any) error
+	// TriggerContext is the same as Trigger, but with the context, it is passed to the guards and callbacks by the Event.
+	// If the context is done before the leave state callbacks, the transform is canceled with ErrCanceled
+	// which wraps the context error, once they are called the transform is committed.
+	TriggerContext(ctx context.Context, event E, args ...any) error
+	// Undo moves the current state back to the source state of the last successful trigger,
+	// the callbacks of the event are called with Event.Undo set, but the guards are not evaluated.
+	// It returns ErrNothingToUndo if there is no trigger to undo or the Fsm is not constructed with WithUndo,
+	// ErrIrreversible if the transform is not marked Reversible with WithUndoReversibleOnly,
+	// or the TriggerError if a before event callback cancels it.
+	// SetCurrent and Restore clear the triggers to undo and redo.
+	Undo() error
+	// Redo triggers the last undone event again with its arguments, the guards and callbacks are called.
+	// It returns ErrNothingToRedo if there is no undone trigger, or the error of the trigger.
+	// A successful trigger clears the triggers to redo.
+	Redo() error
+	// History returns the recorded state changes, the oldest first.
+	// It returns nil unless the Fsm is constructed with WithHistory.
+	History() []HistoryEntry[E, S]
+	// Snapshot returns a serializable value of the Fsm, including the history.
+	Snapshot() Snapshot[E, S]
+	// Restore restores the Fsm from the snapshot, it returns ErrInvalidSnapshot if the version is unsupported,
+	// or the snapshot does not belong to the transition.
+	// The history is replaced by the one of the snapshot if the Fsm is constructed with WithHistory.
+	// The data of the snapshot is copied like Clone does, so the Fsm does not share it with the caller.
+	Restore(snapshot Snapshot[E, S]) error
 	// MatchOccur returns true if event can occur in the current state.
 	MatchCurrentOccur(event E) bool
 	// MatchAllOccur returns true if all the events can occur in current state.
@@ -38,6 +82,35 @@ type IFsm[E constraints.Ordered, S constraints.Ordered] interface {
 	Diagram
 }
 
+// FsmData returns the data attached to the Fsm as type D,
+// ok reports whether the data is type D.
+func FsmData[D any, E constraints.Ordered, S constraints.Ordered](f IFsm[E, S]) (data D, ok bool) {
+	data, ok = f.Data().(D)
+	return data, ok
+}
+
+// UpdateFsmData replaces the data attached to the Fsm with the result of fn called with the current data as type D,
+// fn gets the zero value of D if the data is not type D. It is atomic as UpdateData.
+func UpdateFsmData[D any, E constraints.Ordered, S constraints.Ordered](f IFsm[E, S], fn func(data D) D) {
+	f.UpdateData(func(data any) any {
+		d, _ := data.(D)
+		return fn(d)
+	})
+}
+
+// DataCloner is implemented by the data which needs a deep copy when the Fsm is cloned.
+type DataCloner interface {
+	CloneData() any
+}
+
+// cloneData returns a copy of the data.
+func cloneData(data any) any {
+	if c, ok := data.(DataCloner); ok {
+		return c.CloneData()
+	}
+	return data
+}
+
 type ErrorTranslator interface {
 	Translate(err error) error
 }
@@ -0,0 +1,223 @@
+package fsm
+
+import (
+	"golang.org/x/exp/slices"
+)
+
+// UnreachableStates returns a list of sorted states which can not be reached from the initial state.
+// The guarded transforms are considered reachable.
+func (t *Transition[E, S]) UnreachableStates(initState S) []S {
+	visited := t.reachable(t.successors(), initState)
+	states := make([]S, 0)
+	for _, state := range t.SortedStates() {
+		if _, ok := visited[state]; !ok {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// TerminalStates returns a list of sorted states which have no outgoing transform.
+func (t *Transition[E, S]) TerminalStates() []S {
+	successors := t.successors()
+	states := make([]S, 0)
+	for _, state := range t.SortedStates() {
+		if len(successors[state]) == 0 {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// DeadStates returns a list of sorted states which can not reach any of the final states.
+// The guarded transforms are considered reachable.
+func (t *Transition[E, S]) DeadStates(finalStates ...S) []S {
+	// walk the reversed graph from the final states.
+	predecessors := make(map[S][]S)
+	for src, dsts := range t.successors() {
+		for _, dst := range dsts {
+			predecessors[dst] = append(predecessors[dst], src)
+		}
+	}
+	visited := t.reachable(predecessors, finalStates...)
+	states := make([]S, 0)
+	for _, state := range t.SortedStates() {
+		if _, ok := visited[state]; !ok {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// StronglyConnectedComponents returns the strongly connected components of the states,
+// the states of each component are sorted, and the components are sorted by their first state.
+func (t *Transition[E, S]) StronglyConnectedComponents() [][]S {
+	successors := t.successors()
+	// Tarjan's strongly connected components algorithm.
+	index := 0
+	indexes := make(map[S]int)
+	lowLinks := make(map[S]int)
+	onStack := make(map[S]bool)
+	stack := make([]S, 0)
+	components := make([][]S, 0)
+
+	var strongConnect func(state S)
+	strongConnect = func(state S) {
+		indexes[state] = index
+		lowLinks[state] = index
+		index++
+		stack = append(stack, state)
+		onStack[state] = true
+		for _, next := range successors[state] {
+			if _, ok := indexes[next]; !ok {
+				strongConnect(next)
+				if lowLinks[next] < lowLinks[state] {
+					lowLinks[state] = lowLinks[next]
+				}
+			} else if onStack[next] && indexes[next] < lowLinks[state] {
+				lowLinks[state] = indexes[next]
+			}
+		}
+		if lowLinks[state] == indexes[state] {
+			component := make([]S, 0)
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == state {
+					break
+				}
+			}
+			slices.Sort(component)
+			components = append(components, component)
+		}
+	}
+	for _, state := range t.SortedStates() {
+		if _, ok := indexes[state]; !ok {
+			strongConnect(state)
+		}
+	}
+	slices.SortFunc(components, func(a, b []S) bool {
+		return a[0] < b[0]
+	})
+	return components
+}
+
+// PathTo returns the shortest list of events which transforms the from state to the to state,
+// ok reports whether the path exists. The guarded transforms are considered passed.
+func (t *Transition[E, S]) PathTo(from, to S) (events []E, ok bool) {
+	type step struct {
+		prev  S
+		event E
+	}
+	edges := t.outEdges()
+	steps := map[S]step{from: {}}
+	queue := []S{from}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		if state == to {
+			ok = true
+			break
+		}
+		for _, edge := range edges[state] {
+			if _, visited := steps[edge.Dst]; !visited {
+				steps[edge.Dst] = step{state, edge.Event}
+				queue = append(queue, edge.Dst)
+			}
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	for state := to; state != from; state = steps[state].prev {
+		events = append(events, steps[state].event)
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	if events == nil {
+		events = []E{}
+	}
+	return events, true
+}
+
+// AllPathsTo returns the lists of events which transforms the from state to the to state without visiting a state twice,
+// at most limit paths are returned, no limit if limit <= 0. The guarded transforms are considered passed.
+func (t *Transition[E, S]) AllPathsTo(from, to S, limit int) [][]E {
+	edges := t.outEdges()
+	paths := make([][]E, 0)
+	visited := map[S]bool{from: true}
+	events := make([]E, 0)
+
+	var walk func(state S) bool
+	walk = func(state S) bool {
+		if state == to {
+			paths = append(paths, slices.Clone(events))
+			return limit <= 0 || len(paths) < limit
+		}
+		for _, edge := range edges[state] {
+			if visited[edge.Dst] {
+				continue
+			}
+			visited[edge.Dst] = true
+			events = append(events, edge.Event)
+			next := walk(edge.Dst)
+			events = events[:len(events)-1]
+			visited[edge.Dst] = false
+			if !next {
+				return false
+			}
+		}
+		return true
+	}
+	walk(from)
+	return paths
+}
+
+// outEdges returns the sorted outgoing edges of each source state.
+func (t *Transition[E, S]) outEdges() map[S][]Edge[E, S] {
+	edges := make(map[S][]Edge[E, S])
+	for _, edge := range t.SortedEdges() {
+		edges[edge.Src] = append(edges[edge.Src], edge)
+	}
+	return edges
+}
+
+// successors returns the sorted destination states of each source state.
+func (t *Transition[E, S]) successors() map[S][]S {
+	successors := make(map[S][]S)
+	for _, edge := range t.SortedEdges() {
+		if !slices.Contains(successors[edge.Src], edge.Dst) {
+			successors[edge.Src] = append(successors[edge.Src], edge.Dst)
+		}
+	}
+	for _, dsts := range successors {
+		slices.Sort(dsts)
+	}
+	return successors
+}
+
+// reachable returns the states which can be reached from the start states, including the start states.
+func (t *Transition[E, S]) reachable(successors map[S][]S, starts ...S) map[S]struct{} {
+	visited := make(map[S]struct{})
+	queue := make([]S, 0, len(starts))
+	for _, state := range starts {
+		if _, ok := visited[state]; !ok {
+			visited[state] = struct{}{}
+			queue = append(queue, state)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, next := range successors[state] {
+			if _, ok := visited[next]; !ok {
+				visited[next] = struct{}{}
+				queue = append(queue, next)
+			}
+		}
+	}
+	return visited
+}
@@ -0,0 +1,101 @@
+package fsm
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func newTestAnalysisTransition() *Transition[string, string] {
+	return NewTransition([]Transform[string, string]{
+		{Event: "submit", Src: []string{"draft"}, Dst: "review"},
+		{Event: "reject", Src: []string{"review"}, Dst: "draft"},
+		{Event: "approve", Src: []string{"review"}, Dst: "published"},
+		{Event: "archive", Src: []string{"published"}, Dst: "archived"},
+		{Event: "loop", Src: []string{"limbo"}, Dst: "limbo"},
+		{Event: "restore", Src: []string{"orphan"}, Dst: "draft"},
+		{Event: "trap", Src: []string{"draft"}, Dst: "trapped"},
+		{Event: "spin", Src: []string{"trapped"}, Dst: "stuck"},
+		{Event: "spin", Src: []string{"stuck"}, Dst: "trapped"},
+	})
+}
+
+func Test_Transition_UnreachableStates(t *testing.T) {
+	ts := newTestAnalysisTransition()
+	got := ts.UnreachableStates("draft")
+	if wanted := []string{"limbo", "orphan"}; !slices.Equal(got, wanted) {
+		t.Errorf("expected unreachable states %v, but got %v", wanted, got)
+	}
+}
+
+func Test_Transition_TerminalStates(t *testing.T) {
+	ts := newTestAnalysisTransition()
+	got := ts.TerminalStates()
+	if wanted := []string{"archived"}; !slices.Equal(got, wanted) {
+		t.Errorf("expected terminal states %v, but got %v", wanted, got)
+	}
+}
+
+func Test_Transition_DeadStates(t *testing.T) {
+	ts := newTestAnalysisTransition()
+	got := ts.DeadStates("archived")
+	if wanted := []string{"limbo", "stuck", "trapped"}; !slices.Equal(got, wanted) {
+		t.Errorf("expected dead states %v, but got %v", wanted, got)
+	}
+}
+
+func Test_Transition_StronglyConnectedComponents(t *testing.T) {
+	ts := newTestAnalysisTransition()
+	got := ts.StronglyConnectedComponents()
+	wanted := [][]string{
+		{"archived"},
+		{"draft", "review"},
+		{"limbo"},
+		{"orphan"},
+		{"published"},
+		{"stuck", "trapped"},
+	}
+	if !reflect.DeepEqual(got, wanted) {
+		t.Errorf("expected strongly connected components %v, but got %v", wanted, got)
+	}
+}
+
+func Test_Transition_PathTo(t *testing.T) {
+	ts := newTestAnalysisTransition()
+	events, ok := ts.PathTo("draft", "archived")
+	if wanted := []string{"submit", "approve", "archive"}; !ok || !slices.Equal(events, wanted) {
+		t.Errorf("expected path %v, but got %v", wanted, events)
+	}
+	events, ok = ts.PathTo("review", "review")
+	if !ok || len(events) != 0 {
+		t.Errorf("expected empty path, but got %v", events)
+	}
+	if events, ok = ts.PathTo("archived", "draft"); ok {
+		t.Errorf("expected no path, but got %v", events)
+	}
+}
+
+func Test_Transition_AllPathsTo(t *testing.T) {
+	ts := NewTransition([]Transform[string, string]{
+		{Event: "submit", Src: []string{"draft"}, Dst: "review"},
+		{Event: "fast-publish", Src: []string{"draft"}, Dst: "published"},
+		{Event: "reject", Src: []string{"review"}, Dst: "draft"},
+		{Event: "approve", Src: []string{"review"}, Dst: "published"},
+		{Event: "escalate", Src: []string{"review"}, Dst: "legal"},
+		{Event: "approve", Src: []string{"legal"}, Dst: "published"},
+	})
+	got := ts.AllPathsTo("draft", "published", 0)
+	wanted := [][]string{
+		{"fast-publish"},
+		{"submit", "approve"},
+		{"submit", "escalate", "approve"},
+	}
+	if !reflect.DeepEqual(got, wanted) {
+		t.Errorf("expected paths %v, but got %v", wanted, got)
+	}
+	got = ts.AllPathsTo("draft", "published", 2)
+	if !reflect.DeepEqual(got, wanted[:2]) {
+		t.Errorf("expected paths %v, but got %v", wanted[:2], got)
+	}
+}
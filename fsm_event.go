@@ -0,0 +1,163 @@
+package fsm
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Event is the info passed to the callbacks when the Fsm transforms.
+type Event[E constraints.Ordered, S constraints.Ordered] struct {
+	// Event is the event which triggered the transform.
+	Event E
+	// Src is the state before the transform.
+	Src S
+	// Dst is the state after the transform.
+	Dst S
+	// Args is the optional arguments passed to Trigger, it is the payload of the event.
+	Args []any
+	// Undo reports whether the transform is the Undo of the event, Src and Dst are the dst and src states of the undone trigger.
+	// The guards are not evaluated for the Undo, the before event callbacks can cancel it by returning an error.
+	Undo bool
+	// ctx is the context passed to TriggerContext.
+	ctx context.Context
+	// data points to the data attached to the Fsm.
+	data *any
+}
+
+// Context returns the context of the event, it is never nil.
+func (e *Event[E, S]) Context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// Data returns the data attached to the Fsm.
+func (e *Event[E, S]) Data() any {
+	if e.data == nil {
+		return nil
+	}
+	return *e.data
+}
+
+// SetData replaces the data attached to the Fsm.
+// NOTE: The data is changed even if the transform is canceled after it.
+func (e *Event[E, S]) SetData(data any) {
+	if e.data != nil {
+		*e.data = data
+	}
+}
+
+// EventData returns the data attached to the Fsm as type D,
+// ok reports whether the data is type D.
+func EventData[D any, E constraints.Ordered, S constraints.Ordered](e *Event[E, S]) (data D, ok bool) {
+	data, ok = e.Data().(D)
+	return data, ok
+}
+
+// probeEvent returns the event info to evaluate the guards in the src state without triggering,
+// the guards see a copy of the data, so they can not change it.
+func probeEvent[E constraints.Ordered, S constraints.Ordered](event E, src S, data any) *Event[E, S] {
+	return &Event[E, S]{Event: event, Src: src, data: &data}
+}
+
+// TriggerError is the error returned by Trigger when the transform is canceled,
+// it records the event and its arguments which caused the error.
+type TriggerError[E constraints.Ordered, S constraints.Ordered] struct {
+	// Event is the event which triggered the transform.
+	Event E
+	// Src is the state when the transform is canceled.
+	Src S
+	// Args is the optional arguments passed to Trigger.
+	Args []any
+	// Err is the cause of the error, it wraps ErrCanceled.
+	Err error
+}
+
+func (e *TriggerError[E, S]) Error() string {
+	return fmt.Sprintf("%v (event: %v, state: %v)", e.Err, e.Event, e.Src)
+}
+
+func (e *TriggerError[E, S]) Unwrap() error { return e.Err }
+
+// canceled returns a TriggerError of the event which wraps ErrCanceled and the cause.
+func (e *Event[E, S]) canceled(err error) error {
+	return &TriggerError[E, S]{
+		Event: e.Event,
+		Src:   e.Src,
+		Args:  e.Args,
+		Err:   fmt.Errorf("%w: %w", ErrCanceled, err),
+	}
+}
+
+// Callback is a function type that callbacks should use.
+// The callbacks of a SafeFsm are called with the lock held, so they must not call back into the same Fsm.
+type Callback[E constraints.Ordered, S constraints.Ordered] func(e *Event[E, S])
+
+// BeforeCallback is a function type that before event callbacks should use.
+// If it returns an error, the transform is canceled and the current state is unchanged.
+// The callbacks of a SafeFsm are called with the lock held, so they must not call back into the same Fsm.
+type BeforeCallback[E constraints.Ordered, S constraints.Ordered] func(e *Event[E, S]) error
+
+// Guard is a function type that guards should use, it reports whether the transform can be taken.
+// The guards of a SafeFsm are called with the lock held, so they must not call back into the same Fsm.
+type Guard[E constraints.Ordered, S constraints.Ordered] func(e *Event[E, S]) bool
+
+// trigger transforms the current state with the named event, the callbacks are called in the order:
+//
+//  1. before event callbacks of the event, then the before any event callbacks.
+//  2. the transform is canceled if the context is done.
+//  3. leave state callbacks of the src state.
+//  4. the current state change to the dst state.
+//  5. enter state callbacks of the dst state.
+//  6. after event callbacks of the event, then the after any event callbacks.
+//
+// If a before event callback returns an error or the context is done before the leave state callbacks,
+// trigger returns a TriggerError which wraps ErrCanceled and the cause.
+// Once the leave state callbacks are called, the transform is committed regardless of the context,
+// so the leave and enter state callbacks are always called in pairs.
+// The leave and enter state callbacks are not called if the dst state is the same as the src state.
+func trigger[E constraints.Ordered, S constraints.Ordered](ctx context.Context, ts ITransition[E, S], current *S, data *any, event E, args ...any) error {
+	e := &Event[E, S]{
+		Event: event,
+		Src:   *current,
+		Args:  args,
+		ctx:   ctx,
+		data:  data,
+	}
+	if err := ctx.Err(); err != nil {
+		return e.canceled(err)
+	}
+	dst, err := transformWith(ts, e)
+	if err != nil {
+		return err
+	}
+	e.Dst = dst
+	return transform(ts, current, e)
+}
+
+// transform changes the current state from the src state to the dst state of the event,
+// the callbacks are called in the order of trigger.
+func transform[E constraints.Ordered, S constraints.Ordered](ts ITransition[E, S], current *S, e *Event[E, S]) error {
+	hooks, ok := ts.(transitionHooks[E, S])
+	if !ok {
+		hooks = noHooks[E, S]{}
+	}
+	if err := hooks.beforeEvent(e); err != nil {
+		return e.canceled(err)
+	}
+	if err := e.Context().Err(); err != nil {
+		return e.canceled(err)
+	}
+	if e.Src != e.Dst {
+		hooks.leaveState(e)
+	}
+	*current = e.Dst
+	if e.Src != e.Dst {
+		hooks.enterState(e)
+	}
+	hooks.afterEvent(e)
+	return nil
+}
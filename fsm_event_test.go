@@ -0,0 +1,415 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func Test_Fsm_EnterLeaveState(t *testing.T) {
+	test_Fsm_EnterLeaveState(t, NewSafeFsm[LampEvent, LampStatus])
+	test_Fsm_EnterLeaveState(t, NewFsm[LampEvent, LampStatus])
+}
+
+func test_Fsm_EnterLeaveState(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
+	var got []string
+
+	record := func(prefix string) Callback[LampEvent, LampStatus] {
+		return func(e *Event[LampEvent, LampStatus]) {
+			got = append(got, prefix+":"+e.Event.String()+":"+e.Src.String()+"->"+e.Dst.String())
+		}
+	}
+	fsm := newFsm(
+		LampStatus_Closed,
+		NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+			{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+			{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+			{Event: LampEvent_Look, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Closed},
+		}).
+			OnLeave(LampStatus_Closed, record("leave1"), record("leave2")).
+			OnEnter(LampStatus_Opened, record("enter")).
+			OnLeave(LampStatus_Opened, record("leave")).
+			OnEnter(LampStatus_Closed, record("enter")).
+			Build(),
+	)
+
+	if err := fsm.Trigger(LampEvent_Open); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	if err := fsm.Trigger(LampEvent_Look); err == nil {
+		t.Error("expected trigger has error")
+	}
+	if err := fsm.Trigger(LampEvent_Close); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	if err := fsm.Trigger(LampEvent_Look); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	wanted := []string{
+		"leave1:open:closed->opened",
+		"leave2:open:closed->opened",
+		"enter:open:closed->opened",
+		"leave:close:opened->closed",
+		"enter:close:opened->closed",
+	}
+	if !slices.Equal(got, wanted) {
+		t.Errorf("expected callbacks %v, but got %v", wanted, got)
+	}
+}
+
+func Test_Fsm_BeforeAfterEvent(t *testing.T) {
+	test_Fsm_BeforeAfterEvent(t, NewSafeFsm[LampEvent, LampStatus])
+	test_Fsm_BeforeAfterEvent(t, NewFsm[LampEvent, LampStatus])
+}
+
+func test_Fsm_BeforeAfterEvent(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
+	var got []string
+
+	errLocked := errors.New("locked")
+	locked := true
+	record := func(prefix string) Callback[LampEvent, LampStatus] {
+		return func(e *Event[LampEvent, LampStatus]) {
+			got = append(got, prefix+":"+e.Event.String())
+		}
+	}
+	fsm := newFsm(
+		LampStatus_Closed,
+		NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+			{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+			{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+		}).
+			BeforeAnyEvent(func(e *Event[LampEvent, LampStatus]) error {
+				got = append(got, "before-any:"+e.Event.String())
+				return nil
+			}).
+			BeforeEvent(LampEvent_Open, func(e *Event[LampEvent, LampStatus]) error {
+				got = append(got, "before:"+e.Event.String())
+				if locked {
+					return errLocked
+				}
+				return nil
+			}).
+			OnLeave(LampStatus_Closed, record("leave")).
+			OnEnter(LampStatus_Opened, record("enter")).
+			AfterAnyEvent(record("after-any")).
+			AfterEvent(LampEvent_Open, record("after")).
+			Build(),
+	)
+
+	err := fsm.Trigger(LampEvent_Open)
+	if !errors.Is(err, ErrCanceled) || !errors.Is(err, errLocked) {
+		t.Errorf("expected error wraps 'ErrCanceled' and callback error, but got %v", err)
+	}
+	if errors.Is(err, ErrInappropriateEvent) || errors.Is(err, ErrNonExistEvent) {
+		t.Errorf("expected canceled error not be transform error")
+	}
+	if !fsm.Is(LampStatus_Closed) {
+		t.Error("expected state to be 'closed'")
+	}
+	locked = false
+	if err = fsm.Trigger(LampEvent_Open); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	if !fsm.Is(LampStatus_Opened) {
+		t.Error("expected state to be 'opened'")
+	}
+	wanted := []string{
+		"before:open",
+		"before:open",
+		"before-any:open",
+		"leave:open",
+		"enter:open",
+		"after:open",
+		"after-any:open",
+	}
+	if !slices.Equal(got, wanted) {
+		t.Errorf("expected callbacks %v, but got %v", wanted, got)
+	}
+}
+
+type testContextKey struct{}
+
+func Test_Fsm_TriggerContext(t *testing.T) {
+	test_Fsm_TriggerContext(t, NewSafeFsm[LampEvent, LampStatus])
+	test_Fsm_TriggerContext(t, NewFsm[LampEvent, LampStatus])
+}
+
+func test_Fsm_TriggerContext(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
+	var got []any
+
+	fsm := newFsm(
+		LampStatus_Closed,
+		NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+			{
+				Event: LampEvent_Open,
+				Src:   []LampStatus{LampStatus_Closed},
+				Dst:   LampStatus_Opened,
+				Guard: func(e *Event[LampEvent, LampStatus]) bool {
+					got = append(got, e.Context().Value(testContextKey{}))
+					return true
+				},
+			},
+			{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+		}).
+			OnEnter(LampStatus_Opened, func(e *Event[LampEvent, LampStatus]) {
+				got = append(got, e.Context().Value(testContextKey{}))
+				got = append(got, e.Args...)
+			}).
+			Build(),
+	)
+
+	ctx := context.WithValue(context.Background(), testContextKey{}, "trace-id")
+	if err := fsm.TriggerContext(ctx, LampEvent_Open, 1, "two"); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	wanted := []any{"trace-id", "trace-id", 1, "two"}
+	if !slices.Equal(got, wanted) {
+		t.Errorf("expected context values and args %v, but got %v", wanted, got)
+	}
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := fsm.TriggerContext(canceledCtx, LampEvent_Close)
+	if !errors.Is(err, ErrCanceled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error wraps 'ErrCanceled' and 'context.Canceled', but got %v", err)
+	}
+	if !fsm.Is(LampStatus_Opened) {
+		t.Error("expected state to be 'opened'")
+	}
+}
+
+func Test_Fsm_TriggerContext_CancelBeforeCommit(t *testing.T) {
+	test_Fsm_TriggerContext_CancelBeforeCommit(t, NewSafeFsm[LampEvent, LampStatus])
+	test_Fsm_TriggerContext_CancelBeforeCommit(t, NewFsm[LampEvent, LampStatus])
+}
+
+func test_Fsm_TriggerContext_CancelBeforeCommit(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var left, entered bool
+	var cancelLeave context.CancelFunc
+	fsm := newFsm(
+		LampStatus_Closed,
+		NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+			{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+			{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+		}).
+			BeforeEvent(LampEvent_Open, func(e *Event[LampEvent, LampStatus]) error {
+				cancel()
+				return nil
+			}).
+			OnLeave(LampStatus_Closed, func(e *Event[LampEvent, LampStatus]) { left = true }).
+			OnLeave(LampStatus_Opened, func(e *Event[LampEvent, LampStatus]) { cancelLeave() }).
+			OnEnter(LampStatus_Opened, func(e *Event[LampEvent, LampStatus]) { entered = true }).
+			OnEnter(LampStatus_Closed, func(e *Event[LampEvent, LampStatus]) { entered = true }).
+			Build(),
+	)
+	// canceled by a before event callback, the leave state callbacks are not called.
+	err := fsm.TriggerContext(ctx, LampEvent_Open)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error wraps 'context.Canceled', but got %v", err)
+	}
+	if !fsm.Is(LampStatus_Closed) || left || entered {
+		t.Error("expected state to be 'closed' and neither left 'closed' nor entered 'opened'")
+	}
+
+	// canceled by a leave state callback, the transform is committed with the enter state callbacks.
+	fsm.SetCurrent(LampStatus_Opened)
+	ctx, cancelLeave = context.WithCancel(context.Background())
+	defer cancelLeave()
+	if err = fsm.TriggerContext(ctx, LampEvent_Close); err != nil {
+		t.Errorf("expected the transform to be committed, but got %v", err)
+	}
+	if !fsm.Is(LampStatus_Closed) || !entered {
+		t.Error("expected state to be 'closed' and entered 'closed'")
+	}
+}
+
+func Test_Fsm_TriggerPayload(t *testing.T) {
+	test_Fsm_TriggerPayload(t, NewSafeFsm[string, string])
+	test_Fsm_TriggerPayload(t, NewFsm[string, string])
+}
+
+func test_Fsm_TriggerPayload(t *testing.T, newFsm func(initState string, ts ITransition[string, string], opts ...Option) IFsm[string, string]) {
+	errTooLarge := errors.New("too large")
+	amountOf := func(e *Event[string, string]) int {
+		if len(e.Args) > 0 {
+			if amount, ok := e.Args[0].(int); ok {
+				return amount
+			}
+		}
+		return 0
+	}
+	fsm := newFsm(
+		"pending",
+		NewTransitionBuilder([]Transform[string, string]{
+			{Event: "approve", Src: []string{"pending"}, Dst: "approved", Guard: func(e *Event[string, string]) bool { return amountOf(e) < 1000 }},
+			{Event: "approve", Src: []string{"pending"}, Dst: "escalated"},
+		}).
+			BeforeEvent("approve", func(e *Event[string, string]) error {
+				if amountOf(e) > 10000 {
+					return errTooLarge
+				}
+				return nil
+			}).
+			Build(),
+	)
+
+	err := fsm.Trigger("approve", 20000)
+	var triggerErr *TriggerError[string, string]
+	if !errors.As(err, &triggerErr) {
+		t.Fatalf("expected a 'TriggerError', but got %v", err)
+	}
+	if triggerErr.Event != "approve" || triggerErr.Src != "pending" || !slices.Equal(triggerErr.Args, []any{20000}) {
+		t.Errorf("expected trigger error with the event payload, but got %+v", triggerErr)
+	}
+	if !errors.Is(err, ErrCanceled) || !errors.Is(err, errTooLarge) {
+		t.Errorf("expected error wraps 'ErrCanceled' and callback error, but got %v", err)
+	}
+	if err = fsm.Trigger("approve", 5000); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	if !fsm.Is("escalated") {
+		t.Errorf("expected state to be 'escalated', but got %s", fsm.Current())
+	}
+}
+
+type testCounter struct {
+	opened int
+	limit  int
+}
+
+type testCounterRef struct{ *testCounter }
+
+func (c testCounterRef) CloneData() any {
+	v := *c.testCounter
+	return testCounterRef{&v}
+}
+
+func Test_Fsm_Data(t *testing.T) {
+	test_Fsm_Data(t, NewSafeFsm[LampEvent, LampStatus])
+	test_Fsm_Data(t, NewFsm[LampEvent, LampStatus])
+}
+
+func test_Fsm_Data(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
+	fsm := newFsm(
+		LampStatus_Closed,
+		NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+			{
+				Event: LampEvent_Open,
+				Src:   []LampStatus{LampStatus_Closed},
+				Dst:   LampStatus_Opened,
+				Guard: func(e *Event[LampEvent, LampStatus]) bool {
+					c, _ := EventData[testCounter](e)
+					return c.opened < c.limit
+				},
+			},
+			{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+		}).
+			OnEnter(LampStatus_Opened, func(e *Event[LampEvent, LampStatus]) {
+				c, _ := EventData[testCounter](e)
+				c.opened++
+				e.SetData(c)
+			}).
+			Build(),
+	)
+	fsm.SetData(testCounter{limit: 1})
+
+	if err := fsm.Trigger(LampEvent_Open); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	if c := fsm.Data().(testCounter); c.opened != 1 {
+		t.Errorf("expected opened count 1, but got %d", c.opened)
+	}
+	if err := fsm.Trigger(LampEvent_Close); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	if err := fsm.Trigger(LampEvent_Open); err != ErrInappropriateEvent {
+		t.Errorf("expected 'ErrInappropriateEvent' with opened limit, but got %v", err)
+	}
+
+	fsm1 := fsm.Clone()
+	fsm1.SetData(testCounter{limit: 2})
+	if c := fsm.Data().(testCounter); c.limit != 1 {
+		t.Errorf("expected cloned Fsm not change the data, but got %+v", c)
+	}
+	if err := fsm1.Trigger(LampEvent_Open); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+
+	fsm.SetData(testCounterRef{&testCounter{limit: 1}})
+	fsm2 := fsm.CloneNewState(LampStatus_Opened)
+	fsm2.Data().(testCounterRef).opened = 10
+	if c := fsm.Data().(testCounterRef); c.opened != 0 {
+		t.Errorf("expected data cloned by CloneData, but got %+v", c.testCounter)
+	}
+}
+
+func Test_SafeFsm_Data_Concurrent(t *testing.T) {
+	fsm := NewSafeFsm[LampEvent, LampStatus](
+		LampStatus_Closed,
+		NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+			{Event: LampEvent_Look, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Closed},
+		}).
+			AfterEvent(LampEvent_Look, func(e *Event[LampEvent, LampStatus]) {
+				n, _ := EventData[int](e)
+				e.SetData(n + 1)
+			}).
+			Build(),
+	)
+	fsm.SetData(0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = fsm.Trigger(LampEvent_Look)
+				_ = fsm.Data()
+			}
+		}()
+	}
+	wg.Wait()
+	if n := fsm.Data().(int); n != 1000 {
+		t.Errorf("expected data 1000, but got %d", n)
+	}
+}
+
+func Test_Fsm_HooksUnexported(t *testing.T) {
+	typ := reflect.TypeOf((*IFsm[string, string])(nil)).Elem()
+	for _, name := range []string{"BeforeEvent", "LeaveState", "EnterState", "AfterEvent"} {
+		if _, ok := typ.MethodByName(name); ok {
+			t.Errorf("expected %s not to be a method of IFsm", name)
+		}
+	}
+}
+
+func Test_SafeFsm_UpdateData_Concurrent(t *testing.T) {
+	fsm := NewSafeFsm[LampEvent, LampStatus](LampStatus_Closed, NewTransition([]Transform[LampEvent, LampStatus]{
+		{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				UpdateFsmData(fsm, func(n int) int { return n + 1 })
+			}
+		}()
+	}
+	wg.Wait()
+	if n, ok := FsmData[int](fsm); !ok || n != 1000 {
+		t.Errorf("expected data 1000, but got %v", fsm.Data())
+	}
+	if _, ok := FsmData[string](fsm); ok {
+		t.Errorf("expected data not to be a string")
+	}
+}
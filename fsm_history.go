@@ -0,0 +1,167 @@
+package fsm
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/exp/constraints"
+)
+
+// HistoryKind is the kind of the state change recorded in the history.
+type HistoryKind string
+
+const (
+	// HistoryTrigger is recorded by Trigger and TriggerContext, whether it succeeds or not.
+	HistoryTrigger HistoryKind = "trigger"
+	// HistorySetCurrent is recorded by SetCurrent.
+	HistorySetCurrent HistoryKind = "set_current"
+	// HistoryUndo is recorded by Undo, whether it succeeds or not, the Event is the undone event.
+	HistoryUndo HistoryKind = "undo"
+	// HistoryRedo is recorded by Redo, whether it succeeds or not.
+	HistoryRedo HistoryKind = "redo"
+	// HistoryRestore is the kind of the Change delivered by Restore, it is not recorded in the history,
+	// which is replaced by the one of the snapshot.
+	HistoryRestore HistoryKind = "restore"
+)
+
+// HistoryEntry is a record of the history, it round-trips through encoding/json and encoding/gob as part of the Snapshot.
+//
+// NOTE: The Args are encoded as is, the same as the Data of the Snapshot.
+type HistoryEntry[E constraints.Ordered, S constraints.Ordered] struct {
+	// Kind is the kind of the state change.
+	Kind HistoryKind `json:"kind"`
+	// Event is the triggered event, it is the zero value for HistorySetCurrent.
+	Event E `json:"event"`
+	// From is the state before the change.
+	From S `json:"from"`
+	// To is the state after the change, it is the same as From if the trigger failed.
+	To S `json:"to"`
+	// Time is the time of the change given by the clock.
+	Time time.Time `json:"time"`
+	// Error is the error message of the failed trigger.
+	Error string `json:"error,omitempty"`
+	// Actor is the actor given by ContextWithHistoryMeta.
+	Actor string `json:"actor,omitempty"`
+	// Reason is the reason given by ContextWithHistoryMeta.
+	Reason string `json:"reason,omitempty"`
+	// Args are the arguments of the event.
+	Args []any `json:"args,omitempty"`
+}
+
+// HistoryMeta is the optional metadata of the trigger recorded in the history.
+type HistoryMeta struct {
+	// Actor is who triggers the event.
+	Actor string
+	// Reason is why the event is triggered.
+	Reason string
+}
+
+type historyMetaKey struct{}
+
+// ContextWithHistoryMeta returns a copy of the context with the metadata,
+// which is recorded in the history by TriggerContext.
+func ContextWithHistoryMeta(ctx context.Context, meta HistoryMeta) context.Context {
+	return context.WithValue(ctx, historyMetaKey{}, meta)
+}
+
+// HistoryMetaFromContext returns the metadata of the context, if any.
+func HistoryMetaFromContext(ctx context.Context) (HistoryMeta, bool) {
+	meta, ok := ctx.Value(historyMetaKey{}).(HistoryMeta)
+	return meta, ok
+}
+
+// history is the bounded recorder of the state changes.
+type history[E constraints.Ordered, S constraints.Ordered] struct {
+	limit   int
+	clock   func() time.Time
+	entries []HistoryEntry[E, S]
+}
+
+func newHistory[E constraints.Ordered, S constraints.Ordered](o *options) *history[E, S] {
+	if !o.history {
+		return nil
+	}
+	return &history[E, S]{
+		limit: o.historyLimit,
+		clock: o.clock,
+	}
+}
+
+// record records the entry and drops the oldest one if the history is full, it is a no-op if the history is disabled.
+func (h *history[E, S]) record(ctx context.Context, entry HistoryEntry[E, S]) {
+	if h == nil {
+		return
+	}
+	entry.Time = h.clock()
+	if meta, ok := HistoryMetaFromContext(ctx); ok {
+		entry.Actor = meta.Actor
+		entry.Reason = meta.Reason
+	}
+	h.entries = append(h.entries, entry)
+	if h.limit > 0 && len(h.entries) > h.limit {
+		h.entries = append(h.entries[:0:0], h.entries[len(h.entries)-h.limit:]...)
+	}
+}
+
+// recordTrigger records the trigger of the event from the state with the result.
+func (h *history[E, S]) recordTrigger(ctx context.Context, event E, from, to S, args []any, err error) {
+	if h == nil {
+		return
+	}
+	entry := HistoryEntry[E, S]{
+		Kind:  HistoryTrigger,
+		Event: event,
+		From:  from,
+		To:    to,
+		Args:  args,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	h.record(ctx, entry)
+}
+
+// recordSetCurrent records the state set by SetCurrent.
+func (h *history[E, S]) recordSetCurrent(from, to S) {
+	h.record(context.Background(), HistoryEntry[E, S]{
+		Kind: HistorySetCurrent,
+		From: from,
+		To:   to,
+	})
+}
+
+// list returns a copy of the entries, the oldest first.
+func (h *history[E, S]) list() []HistoryEntry[E, S] {
+	if h == nil || len(h.entries) == 0 {
+		return nil
+	}
+	entries := make([]HistoryEntry[E, S], len(h.entries))
+	copy(entries, h.entries)
+	return entries
+}
+
+// restore replaces the entries with the restored ones, only the latest ones within the limit are kept.
+func (h *history[E, S]) restore(entries []HistoryEntry[E, S]) {
+	if h == nil {
+		return
+	}
+	if h.limit > 0 && len(entries) > h.limit {
+		entries = entries[len(entries)-h.limit:]
+	}
+	h.entries = append([]HistoryEntry[E, S](nil), entries...)
+}
+
+// clone returns a copy of the history, with the entries if withEntries is true.
+func (h *history[E, S]) clone(withEntries bool) *history[E, S] {
+	if h == nil {
+		return nil
+	}
+	c := &history[E, S]{
+		limit: h.limit,
+		clock: h.clock,
+	}
+	if withEntries {
+		c.entries = h.list()
+	}
+	return c
+}
@@ -0,0 +1,138 @@
+package fsm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestClock() func() time.Time {
+	now := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func Test_Fsm_History(t *testing.T) {
+	test_Fsm_History(t, NewSafeFsm[LampEvent, LampStatus])
+	test_Fsm_History(t, NewFsm[LampEvent, LampStatus])
+}
+
+func test_Fsm_History(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
+	ts := NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+		{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+		{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+	}).
+		Name("lamp").
+		BeforeEvent(LampEvent_Close, func(e *Event[LampEvent, LampStatus]) error {
+			if len(e.Args) > 0 && e.Args[0] == "deny" {
+				return errors.New("denied")
+			}
+			return nil
+		}).
+		Build()
+
+	fsm := newFsm(LampStatus_Closed, ts, WithHistory(3), WithClock(newTestClock()))
+	ctx := ContextWithHistoryMeta(context.Background(), HistoryMeta{Actor: "alice", Reason: "ticket-1"})
+	if err := fsm.TriggerContext(ctx, LampEvent_Open, "on"); err != nil {
+		t.Fatalf("trigger failed %v", err)
+	}
+	if err := fsm.Trigger(LampEvent_Open); !errors.Is(err, ErrInappropriateEvent) {
+		t.Fatalf("expected ErrInappropriateEvent, but got %v", err)
+	}
+	if err := fsm.Trigger(LampEvent_Close, "deny"); !errors.Is(err, ErrCanceled) {
+		t.Fatalf("expected ErrCanceled, but got %v", err)
+	}
+	fsm.SetCurrent(LampStatus_Closed)
+
+	at := func(sec int) time.Time { return time.Date(2023, 7, 1, 0, 0, sec, 0, time.UTC) }
+	want := []HistoryEntry[LampEvent, LampStatus]{
+		{
+			Kind:  HistoryTrigger,
+			Event: LampEvent_Open,
+			From:  LampStatus_Opened,
+			To:    LampStatus_Opened,
+			Time:  at(2),
+			Error: ErrInappropriateEvent.Error(),
+		},
+		{
+			Kind:  HistoryTrigger,
+			Event: LampEvent_Close,
+			From:  LampStatus_Opened,
+			To:    LampStatus_Opened,
+			Time:  at(3),
+			Error: "fsm: transition canceled: denied (event: close, state: opened)",
+			Args:  []any{"deny"},
+		},
+		{
+			Kind: HistorySetCurrent,
+			From: LampStatus_Opened,
+			To:   LampStatus_Closed,
+			Time: at(4),
+		},
+	}
+	// the first entry is dropped by the limit.
+	if got := fsm.History(); !reflect.DeepEqual(got, want) {
+		t.Errorf("history = %+v, wanted %+v", got, want)
+	}
+
+	t.Run("meta", func(t *testing.T) {
+		f := newFsm(LampStatus_Closed, ts, WithHistory(0), WithClock(newTestClock()))
+		if err := f.TriggerContext(ctx, LampEvent_Open, "on"); err != nil {
+			t.Fatalf("trigger failed %v", err)
+		}
+		want := []HistoryEntry[LampEvent, LampStatus]{
+			{
+				Kind:   HistoryTrigger,
+				Event:  LampEvent_Open,
+				From:   LampStatus_Closed,
+				To:     LampStatus_Opened,
+				Time:   at(1),
+				Actor:  "alice",
+				Reason: "ticket-1",
+				Args:   []any{"on"},
+			},
+		}
+		if got := f.History(); !reflect.DeepEqual(got, want) {
+			t.Errorf("history = %+v, wanted %+v", got, want)
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		f := newFsm(LampStatus_Closed, ts)
+		_ = f.Trigger(LampEvent_Open)
+		f.SetCurrent(LampStatus_Closed)
+		if got := f.History(); got != nil {
+			t.Errorf("expected no history, but got %+v", got)
+		}
+	})
+	t.Run("clone", func(t *testing.T) {
+		if got := fsm.Clone().History(); !reflect.DeepEqual(got, want) {
+			t.Errorf("cloned history = %+v, wanted %+v", got, want)
+		}
+		if got := fsm.CloneNewState(LampStatus_Opened).History(); got != nil {
+			t.Errorf("expected no history of CloneNewState, but got %+v", got)
+		}
+	})
+	t.Run("snapshot", func(t *testing.T) {
+		b, err := json.Marshal(fsm.Snapshot())
+		if err != nil {
+			t.Fatalf("marshal failed %v", err)
+		}
+		var snapshot Snapshot[LampEvent, LampStatus]
+		if err = json.Unmarshal(b, &snapshot); err != nil {
+			t.Fatalf("unmarshal failed %v", err)
+		}
+		restored := newFsm(LampStatus_Opened, ts, WithHistory(2))
+		if err = restored.Restore(snapshot); err != nil {
+			t.Fatalf("restore failed %v", err)
+		}
+		// the restored history is cut by the limit.
+		if got := restored.History(); !reflect.DeepEqual(got, want[1:]) {
+			t.Errorf("restored history = %+v, wanted %+v", got, want[1:])
+		}
+	})
+}
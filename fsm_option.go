@@ -0,0 +1,62 @@
+package fsm
+
+import (
+	"time"
+)
+
+// Option is the option of NewFsm and NewSafeFsm.
+type Option func(*options)
+
+type options struct {
+	history            bool
+	historyLimit       int
+	clock              func() time.Time
+	undo               bool
+	undoDepth          int
+	undoReversibleOnly bool
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		clock: time.Now,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithHistory enables the history which records each trigger, whether it succeeds or not, and each SetCurrent,
+// only the latest limit entries are kept, limit <= 0 means unlimited.
+func WithHistory(limit int) Option {
+	return func(o *options) {
+		o.history = true
+		o.historyLimit = limit
+	}
+}
+
+// WithUndo enables Undo and Redo of the successful triggers,
+// only the latest depth triggers can be undone, depth <= 0 means unlimited.
+func WithUndo(depth int) Option {
+	return func(o *options) {
+		o.undo = true
+		o.undoDepth = depth
+	}
+}
+
+// WithUndoReversibleOnly restricts Undo to the transforms marked Reversible, it implies WithUndo if it is not given.
+func WithUndoReversibleOnly() Option {
+	return func(o *options) {
+		o.undo = true
+		o.undoReversibleOnly = true
+	}
+}
+
+// WithClock sets the clock of the history timestamps, default time.Now.
+func WithClock(clock func() time.Time) Option {
+	return func(o *options) {
+		if clock != nil {
+			o.clock = clock
+		}
+	}
+}
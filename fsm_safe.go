@@ -1,13 +1,16 @@
 package fsm
 
 import (
+	"context"
 	"sync"
 
 	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
 )
 
 var _ IFsm[string, string] = (*SafeFsm[string, string])(nil)
 var _ IFsm[int, string] = (*SafeFsm[int, string])(nil)
+var _ Subscriber[string, string] = (*SafeFsm[string, string])(nil)
 
 // SafeFsm is the state machine that holds the current state and mutex.
 // E is the event
@@ -16,31 +19,52 @@ type SafeFsm[E constraints.Ordered, S constraints.Ordered] struct {
 	// Transition contain events and source states to destination states.
 	// This is immutable
 	ITransition[E, S]
-	// mu guards access to the current state.
+	// mu guards access to the current state and data.
 	mu sync.RWMutex
 	// current is the state that the Fsm is currently in.
 	current S
+	// data is the data attached to the Fsm.
+	data any
+	// history records the state changes, nil if it is disabled.
+	history *history[E, S]
+	// undo is the stacks of Undo and Redo, nil if it is disabled.
+	undo *undoStack[E, S]
+	// subscribers receive the state changes.
+	subscribers subscribers[E, S]
 }
 
-// NewSafeFsm constructs a generic Fsm with an initial state S and a transition.
+// NewSafeFsm constructs a generic Fsm with an initial state S, a transition and the options.
 // E is the event type
 // S is the state type.
-func NewSafeFsm[E constraints.Ordered, S constraints.Ordered](initState S, ts ITransition[E, S]) IFsm[E, S] {
+func NewSafeFsm[E constraints.Ordered, S constraints.Ordered](initState S, ts ITransition[E, S], opts ...Option) IFsm[E, S] {
+	o := newOptions(opts...)
 	return &SafeFsm[E, S]{
 		current:     initState,
 		ITransition: ts,
+		history:     newHistory[E, S](o),
+		undo:        newUndoStack[E, S](o),
 	}
 }
 func (f *SafeFsm[E, S]) Clone() IFsm[E, S] {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 	return &SafeFsm[E, S]{
 		current:     f.current,
+		data:        cloneData(f.data),
 		ITransition: f.ITransition,
+		history:     f.history.clone(true),
+		undo:        f.undo.clone(true),
 	}
 }
 func (f *SafeFsm[E, S]) CloneNewState(newState S) IFsm[E, S] {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 	return &SafeFsm[E, S]{
 		current:     newState,
+		data:        cloneData(f.data),
 		ITransition: f.ITransition,
+		history:     f.history.clone(false),
+		undo:        f.undo.clone(false),
 	}
 }
 func (f *SafeFsm[E, S]) Current() S {
@@ -49,34 +73,151 @@ func (f *SafeFsm[E, S]) Current() S {
 	return f.current
 }
 func (f *SafeFsm[E, S]) SetCurrent(newState S) {
+	f.update(func() *Change[E, S] {
+		from := f.current
+		f.history.recordSetCurrent(from, newState)
+		f.undo.reset()
+		f.current = newState
+		return &Change[E, S]{Kind: HistorySetCurrent, From: from, To: newState}
+	})
+}
+func (f *SafeFsm[E, S]) Data() any {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.data
+}
+func (f *SafeFsm[E, S]) SetData(data any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data = data
+}
+func (f *SafeFsm[E, S]) UpdateData(fn func(data any) any) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
-	f.current = newState
+	f.data = fn(f.data)
 }
 func (f *SafeFsm[E, S]) Is(state S) bool {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 	return state == f.current
 }
-func (f *SafeFsm[E, S]) Trigger(event E) error {
-	f.mu.Lock()
-	defer f.mu.Unlock()
-	dst, err := f.ITransition.Transform(f.current, event)
+func (f *SafeFsm[E, S]) Trigger(event E, args ...any) error {
+	return f.TriggerContext(context.Background(), event, args...)
+}
+func (f *SafeFsm[E, S]) TriggerContext(ctx context.Context, event E, args ...any) error {
+	var err error
+	f.update(func() *Change[E, S] {
+		from := f.current
+		err = trigger(ctx, f.ITransition, &f.current, &f.data, event, args...)
+		f.history.recordTrigger(ctx, event, from, f.current, args, err)
+		f.undo.recordTrigger(event, from, f.current, args, err)
+		return f.change(HistoryTrigger, event, from, err)
+	})
+	return err
+}
+func (f *SafeFsm[E, S]) Undo() error {
+	var err error
+	f.update(func() *Change[E, S] {
+		var event E
+		from := f.current
+		event, err = undo(f.ITransition, &f.current, &f.data, f.undo, f.history)
+		return f.change(HistoryUndo, event, from, err)
+	})
+	return err
+}
+func (f *SafeFsm[E, S]) Redo() error {
+	var err error
+	f.update(func() *Change[E, S] {
+		var event E
+		from := f.current
+		event, err = redo(f.ITransition, &f.current, &f.data, f.undo, f.history)
+		return f.change(HistoryRedo, event, from, err)
+	})
+	return err
+}
+
+// Subscribe returns a channel which receives the change after each successful Trigger, Undo, Redo, SetCurrent and Restore,
+// in the order of the changes, and the function to unsubscribe which closes the channel.
+// The buffer size of the channel and the policy when it is full are set by the options.
+func (f *SafeFsm[E, S]) Subscribe(opts ...SubscribeOption) (<-chan Change[E, S], func()) {
+	return f.subscribers.subscribe(opts...)
+}
+
+// update changes the Fsm by fn with the lock, then delivers the change returned by fn to the subscribers,
+// the changes are serialized by the delivery lock, so they are delivered in order.
+func (f *SafeFsm[E, S]) update(fn func() *Change[E, S]) {
+	f.subscribers.deliverMu.Lock()
+	defer f.subscribers.deliverMu.Unlock()
+	c := func() *Change[E, S] {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return fn()
+	}()
+	if c != nil {
+		f.subscribers.deliver(*c)
+	}
+}
+
+// change returns the change to the current state, nil if the change failed.
+func (f *SafeFsm[E, S]) change(kind HistoryKind, event E, from S, err error) *Change[E, S] {
 	if err != nil {
+		return nil
+	}
+	return &Change[E, S]{Kind: kind, Event: event, From: from, To: f.current}
+}
+func (f *SafeFsm[E, S]) History() []HistoryEntry[E, S] {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.history.list()
+}
+func (f *SafeFsm[E, S]) Snapshot() Snapshot[E, S] {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return newSnapshot(f.ITransition, f.current, f.data, f.history.list())
+}
+func (f *SafeFsm[E, S]) Restore(snapshot Snapshot[E, S]) error {
+	if err := verifySnapshot(f.ITransition, snapshot); err != nil {
 		return err
 	}
-	f.current = dst
+	f.update(func() *Change[E, S] {
+		from := f.current
+		f.current = snapshot.State
+		f.data = cloneData(snapshot.Data)
+		f.history.restore(snapshot.History)
+		f.undo.reset()
+		return &Change[E, S]{Kind: HistoryRestore, From: from, To: f.current}
+	})
 	return nil
 }
 func (f *SafeFsm[E, S]) MatchCurrentOccur(event E) bool {
-	return f.ITransition.MatchOccur(f.current, event)
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return matchOccurWith(f.ITransition, probeEvent(event, f.current, f.data))
 }
-func (f *SafeFsm[E, S]) MatchCurrentAllOccur(event ...E) bool {
-	return f.ITransition.MatchAllOccur(f.current, event...)
+func (f *SafeFsm[E, S]) MatchCurrentAllOccur(events ...E) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var zero E
+	occurEvents := availEventsWith(f.ITransition, probeEvent(zero, f.current, f.data))
+	for _, event := range events {
+		if !slices.Contains(occurEvents, event) {
+			return false
+		}
+	}
+	return true
 }
 func (f *SafeFsm[E, S]) CurrentAvailEvents() []E {
-	return f.ITransition.AvailEvents(f.current)
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var zero E
+	return availEventsWith(f.ITransition, probeEvent(zero, f.current, f.data))
+}
+func (f *SafeFsm[E, S]) SortedEdges() []Edge[E, S] {
+	return sortedEdges[E, S](f.ITransition)
 }
 func (f *SafeFsm[E, S]) Visualize(t VisualizeType) (string, error) {
 	return Visualize[E, S](t, f)
 }
+func (f *SafeFsm[E, S]) VisualizeWithOptions(t VisualizeType, opts ...VisualizeOption) (string, error) {
+	return VisualizeWithOptions[E, S](t, f, opts...)
+}
@@ -0,0 +1,56 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// SnapshotVersion is the version of the snapshot format.
+const SnapshotVersion = 1
+
+var ErrInvalidSnapshot = errors.New("fsm: invalid snapshot")
+
+// Snapshot is a serializable value of the Fsm, it round-trips through encoding/json and encoding/gob.
+//
+// NOTE: The Data is encoded as is, register its concrete type by gob.Register for encoding/gob.
+// encoding/json decodes it into generic JSON values, unless Data is set to a pointer of the concrete type before decoding,
+// then Data is that pointer, dereference it before Restore if the Fsm holds the value rather than the pointer.
+type Snapshot[E constraints.Ordered, S constraints.Ordered] struct {
+	// Version is the version of the snapshot format.
+	Version int `json:"version"`
+	// Name is the name of the transition.
+	Name string `json:"name"`
+	// State is the current state of the Fsm.
+	State S `json:"state"`
+	// Data is the data attached to the Fsm.
+	Data any `json:"data,omitempty"`
+	// History is the history of the Fsm, the oldest first.
+	History []HistoryEntry[E, S] `json:"history,omitempty"`
+}
+
+// newSnapshot returns a snapshot of the Fsm.
+func newSnapshot[E constraints.Ordered, S constraints.Ordered](ts ITransition[E, S], current S, data any, history []HistoryEntry[E, S]) Snapshot[E, S] {
+	return Snapshot[E, S]{
+		Version: SnapshotVersion,
+		Name:    ts.Name(),
+		State:   current,
+		Data:    cloneData(data),
+		History: history,
+	}
+}
+
+// verifySnapshot reports whether the snapshot can be restored with the transition.
+func verifySnapshot[E constraints.Ordered, S constraints.Ordered](ts ITransition[E, S], snapshot Snapshot[E, S]) error {
+	if snapshot.Version != SnapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, snapshot.Version)
+	}
+	if snapshot.Name != ts.Name() {
+		return fmt.Errorf("%w: transition name %q mismatch %q", ErrInvalidSnapshot, snapshot.Name, ts.Name())
+	}
+	if !ts.ContainsState(snapshot.State) {
+		return fmt.Errorf("%w: state %v does not belong to the transition", ErrInvalidSnapshot, snapshot.State)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package fsm
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type testOrder struct {
+	ID     string
+	Amount int
+}
+
+func init() {
+	gob.Register(testOrder{})
+}
+
+func newTestSnapshotTransition() *Transition[LampEvent, LampStatus] {
+	return NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+		{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+		{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+	}).
+		Name("lamp").
+		Build()
+}
+
+func Test_Fsm_Snapshot(t *testing.T) {
+	test_Fsm_Snapshot(t, NewSafeFsm[LampEvent, LampStatus])
+	test_Fsm_Snapshot(t, NewFsm[LampEvent, LampStatus])
+}
+
+func test_Fsm_Snapshot(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
+	ts := newTestSnapshotTransition()
+	fsm := newFsm(LampStatus_Closed, ts)
+	fsm.SetData(testOrder{ID: "o-1", Amount: 100})
+	if err := fsm.Trigger(LampEvent_Open); err != nil {
+		t.Fatalf("trigger failed %v", err)
+	}
+	snapshot := fsm.Snapshot()
+
+	t.Run("json", func(t *testing.T) {
+		b, err := json.Marshal(snapshot)
+		if err != nil {
+			t.Fatalf("marshal failed %v", err)
+		}
+		got := Snapshot[LampEvent, LampStatus]{Data: &testOrder{}}
+		if err = json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("unmarshal failed %v", err)
+		}
+		// the data is decoded into the pointer, the Fsm holds the value.
+		got.Data = *got.Data.(*testOrder)
+		restored := newFsm(LampStatus_Closed, ts)
+		if err = restored.Restore(got); err != nil {
+			t.Fatalf("restore failed %v", err)
+		}
+		if !restored.Is(LampStatus_Opened) {
+			t.Errorf("expected state to be 'opened', but got %s", restored.Current())
+		}
+		if order, ok := restored.Data().(testOrder); !ok || order != (testOrder{ID: "o-1", Amount: 100}) {
+			t.Errorf("expected restored data, but got %v", restored.Data())
+		}
+	})
+	t.Run("gob", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(snapshot); err != nil {
+			t.Fatalf("encode failed %v", err)
+		}
+		var got Snapshot[LampEvent, LampStatus]
+		if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+			t.Fatalf("decode failed %v", err)
+		}
+		restored := newFsm(LampStatus_Closed, ts)
+		if err := restored.Restore(got); err != nil {
+			t.Fatalf("restore failed %v", err)
+		}
+		if !restored.Is(LampStatus_Opened) {
+			t.Errorf("expected state to be 'opened', but got %s", restored.Current())
+		}
+		if order, ok := restored.Data().(testOrder); !ok || order != (testOrder{ID: "o-1", Amount: 100}) {
+			t.Errorf("expected restored data, but got %v", restored.Data())
+		}
+	})
+}
+
+func Test_Fsm_Restore_Data(t *testing.T) {
+	test_Fsm_Restore_Data(t, NewSafeFsm[LampEvent, LampStatus])
+	test_Fsm_Restore_Data(t, NewFsm[LampEvent, LampStatus])
+}
+
+func test_Fsm_Restore_Data(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
+	counter := &testCounter{opened: 1, limit: 2}
+	fsm := newFsm(LampStatus_Closed, newTestSnapshotTransition())
+	snapshot := Snapshot[LampEvent, LampStatus]{Version: SnapshotVersion, Name: "lamp", State: LampStatus_Opened, Data: testCounterRef{counter}}
+	if err := fsm.Restore(snapshot); err != nil {
+		t.Fatalf("restore failed %v", err)
+	}
+	counter.opened = 2
+	if c := fsm.Data().(testCounterRef); c.opened != 1 {
+		t.Errorf("expected restored data not to alias the snapshot, but got opened count %d", c.opened)
+	}
+}
+
+func Test_Fsm_Restore_Invalid(t *testing.T) {
+	fsm := NewFsm[LampEvent, LampStatus](LampStatus_Closed, newTestSnapshotTransition())
+	for _, snapshot := range []Snapshot[LampEvent, LampStatus]{
+		{Version: SnapshotVersion + 1, Name: "lamp", State: LampStatus_Opened},
+		{Version: SnapshotVersion, Name: "door", State: LampStatus_Opened},
+		{Version: SnapshotVersion, Name: "lamp", State: LampStatus_Intermediate},
+	} {
+		if err := fsm.Restore(snapshot); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("expected 'ErrInvalidSnapshot' with snapshot %+v, but got %v", snapshot, err)
+		}
+	}
+	if !fsm.Is(LampStatus_Closed) {
+		t.Error("expected state to be 'closed'")
+	}
+}
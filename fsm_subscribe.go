@@ -0,0 +1,140 @@
+package fsm
+
+import (
+	"sync"
+
+	"golang.org/x/exp/constraints"
+)
+
+// SubscribePolicy is the policy of the delivery when the buffer of the subscriber is full.
+type SubscribePolicy int
+
+const (
+	// SubscribeDrop drops the change if the buffer of the subscriber is full, the Fsm is never blocked.
+	SubscribeDrop SubscribePolicy = iota
+	// SubscribeBlock blocks the Fsm until the subscriber receives the change or unsubscribes,
+	// the subscriber must keep receiving without changing the state of the Fsm, the changes wait for the blocked delivery.
+	SubscribeBlock
+)
+
+// defaultSubscribeBuffer is the default buffer size of the subscriber.
+const defaultSubscribeBuffer = 16
+
+// Change is the state change delivered to the subscribers.
+type Change[E constraints.Ordered, S constraints.Ordered] struct {
+	// Kind is the kind of the state change, one of HistoryTrigger, HistorySetCurrent, HistoryUndo, HistoryRedo and HistoryRestore.
+	Kind HistoryKind
+	// Event is the triggered or undone event, it is the zero value for HistorySetCurrent and HistoryRestore.
+	Event E
+	// From is the state before the change.
+	From S
+	// To is the state after the change.
+	To S
+}
+
+// Subscriber is the Fsm which delivers its state changes to the subscribers, SafeFsm implements it.
+type Subscriber[E constraints.Ordered, S constraints.Ordered] interface {
+	// Subscribe returns a channel which receives the state changes and the function to unsubscribe which closes the channel.
+	Subscribe(opts ...SubscribeOption) (<-chan Change[E, S], func())
+}
+
+// SubscribeOption is the option of Subscribe.
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	buffer int
+	policy SubscribePolicy
+}
+
+// WithSubscribeBuffer sets the buffer size of the channel, default 16, buffer < 0 means 0.
+func WithSubscribeBuffer(buffer int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		if buffer < 0 {
+			buffer = 0
+		}
+		o.buffer = buffer
+	}
+}
+
+// WithSubscribePolicy sets the policy when the buffer of the channel is full, default SubscribeDrop.
+func WithSubscribePolicy(policy SubscribePolicy) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.policy = policy
+	}
+}
+
+type subscriber[E constraints.Ordered, S constraints.Ordered] struct {
+	ch     chan Change[E, S]
+	done   chan struct{}
+	policy SubscribePolicy
+}
+
+// send delivers the change to the subscriber according to its policy.
+func (s *subscriber[E, S]) send(c Change[E, S]) {
+	if s.policy == SubscribeBlock {
+		select {
+		case s.ch <- c:
+		case <-s.done:
+		}
+		return
+	}
+	select {
+	case s.ch <- c:
+	default:
+	}
+}
+
+// subscribers are the subscribers of the Fsm, the zero value is ready to use.
+type subscribers[E constraints.Ordered, S constraints.Ordered] struct {
+	// mu guards the list of the subscribers.
+	mu   sync.Mutex
+	list []*subscriber[E, S]
+	// deliverMu serializes the changes and their deliveries, so the changes are delivered in order.
+	// It is locked before the lock of the Fsm.
+	deliverMu sync.Mutex
+}
+
+func (ss *subscribers[E, S]) subscribe(opts ...SubscribeOption) (<-chan Change[E, S], func()) {
+	o := &subscribeOptions{buffer: defaultSubscribeBuffer}
+	for _, opt := range opts {
+		opt(o)
+	}
+	s := &subscriber[E, S]{
+		ch:     make(chan Change[E, S], o.buffer),
+		done:   make(chan struct{}),
+		policy: o.policy,
+	}
+	ss.mu.Lock()
+	ss.list = append(ss.list, s)
+	ss.mu.Unlock()
+
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			// unblock the delivery to the subscriber, then wait for it to finish before closing the channel.
+			close(s.done)
+			ss.deliverMu.Lock()
+			defer ss.deliverMu.Unlock()
+			ss.mu.Lock()
+			defer ss.mu.Unlock()
+			for i, v := range ss.list {
+				if v == s {
+					ss.list = append(ss.list[:i:i], ss.list[i+1:]...)
+					break
+				}
+			}
+			close(s.ch)
+		})
+	}
+}
+
+// deliver delivers the change to the subscribers, deliverMu must be locked,
+// so the unsubscribed ones are removed and the channels of the list are not closed.
+func (ss *subscribers[E, S]) deliver(c Change[E, S]) {
+	ss.mu.Lock()
+	list := ss.list
+	ss.mu.Unlock()
+	for _, s := range list {
+		s.send(c)
+	}
+}
@@ -0,0 +1,134 @@
+package fsm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestSubscribeFsm() *SafeFsm[LampEvent, LampStatus] {
+	return NewSafeFsm[LampEvent, LampStatus](LampStatus_Closed, newTestSnapshotTransition(), WithUndo(0)).(*SafeFsm[LampEvent, LampStatus])
+}
+
+func receiveChanges(t *testing.T, ch <-chan Change[LampEvent, LampStatus], n int) []Change[LampEvent, LampStatus] {
+	t.Helper()
+	changes := make([]Change[LampEvent, LampStatus], 0, n)
+	for i := 0; i < n; i++ {
+		select {
+		case c := <-ch:
+			changes = append(changes, c)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout to receive the change #%d", i)
+		}
+	}
+	return changes
+}
+
+func Test_SafeFsm_Subscribe(t *testing.T) {
+	fsm := newTestSubscribeFsm()
+	ch, unsubscribe := fsm.Subscribe()
+
+	_ = fsm.Trigger(LampEvent_Open)
+	_ = fsm.Trigger(LampEvent_Open) // failed, not delivered
+	_ = fsm.Undo()
+	_ = fsm.Redo()
+	snapshot := fsm.Snapshot()
+	fsm.SetCurrent(LampStatus_Closed)
+	if err := fsm.Restore(snapshot); err != nil {
+		t.Fatalf("restore failed %v", err)
+	}
+
+	want := []Change[LampEvent, LampStatus]{
+		{Kind: HistoryTrigger, Event: LampEvent_Open, From: LampStatus_Closed, To: LampStatus_Opened},
+		{Kind: HistoryUndo, Event: LampEvent_Open, From: LampStatus_Opened, To: LampStatus_Closed},
+		{Kind: HistoryRedo, Event: LampEvent_Open, From: LampStatus_Closed, To: LampStatus_Opened},
+		{Kind: HistorySetCurrent, From: LampStatus_Opened, To: LampStatus_Closed},
+		{Kind: HistoryRestore, From: LampStatus_Closed, To: LampStatus_Opened},
+	}
+	if got := receiveChanges(t, ch, len(want)); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %+v, wanted %+v", got, want)
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if _, ok := <-ch; ok {
+		t.Errorf("expected the channel to be closed")
+	}
+	_ = fsm.Trigger(LampEvent_Open)
+}
+
+func Test_SafeFsm_Subscribe_Drop(t *testing.T) {
+	fsm := newTestSubscribeFsm()
+	ch, unsubscribe := fsm.Subscribe(WithSubscribeBuffer(1), WithSubscribePolicy(SubscribeDrop))
+	defer unsubscribe()
+
+	_ = fsm.Trigger(LampEvent_Open)
+	_ = fsm.Trigger(LampEvent_Close)
+	_ = fsm.Trigger(LampEvent_Open)
+
+	want := []Change[LampEvent, LampStatus]{
+		{Kind: HistoryTrigger, Event: LampEvent_Open, From: LampStatus_Closed, To: LampStatus_Opened},
+	}
+	if got := receiveChanges(t, ch, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %+v, wanted %+v", got, want)
+	}
+	select {
+	case c := <-ch:
+		t.Errorf("expected the changes to be dropped, but got %+v", c)
+	default:
+	}
+}
+
+func Test_SafeFsm_Subscribe_Block(t *testing.T) {
+	fsm := newTestSubscribeFsm()
+	ch, unsubscribe := fsm.Subscribe(WithSubscribeBuffer(0), WithSubscribePolicy(SubscribeBlock))
+
+	const n = 100
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < n; i++ {
+			if i%2 == 0 {
+				_ = fsm.Trigger(LampEvent_Open)
+			} else {
+				_ = fsm.Trigger(LampEvent_Close)
+			}
+		}
+	}()
+	for i, c := range receiveChanges(t, ch, n) {
+		_ = fsm.Current() // the subscriber can read the Fsm while the delivery is blocked.
+		wantEvent := LampEvent_Open
+		if i%2 == 1 {
+			wantEvent = LampEvent_Close
+		}
+		if c.Event != wantEvent {
+			t.Fatalf("change #%d = %+v, wanted event %s", i, c, wantEvent)
+		}
+	}
+	<-done
+
+	unsubscribe()
+}
+
+func Test_SafeFsm_Subscribe_Unblock(t *testing.T) {
+	fsm := newTestSubscribeFsm()
+	first, unsubscribeFirst := fsm.Subscribe(WithSubscribeBuffer(0), WithSubscribePolicy(SubscribeBlock))
+	defer unsubscribeFirst()
+	blocked, unsubscribe := fsm.Subscribe(WithSubscribeBuffer(0), WithSubscribePolicy(SubscribeBlock))
+
+	done := make(chan error)
+	go func() {
+		done <- fsm.Trigger(LampEvent_Open)
+	}()
+	// the changes are delivered in the order of subscription,
+	// so the delivery goes on to the blocked subscriber which never receives once the first one received.
+	receiveChanges(t, first, 1)
+	// unsubscribe unblocks the delivery, and returns after the delivery finished.
+	unsubscribe()
+	if err := <-done; err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	if _, ok := <-blocked; ok {
+		t.Error("expected the channel closed without the change")
+	}
+}
@@ -56,7 +56,7 @@ func Test_Fsm_Clone(t *testing.T) {
 	test_Fsm_Clone(t, NewFsm[LampEvent, LampStatus])
 }
 
-func test_Fsm_Clone(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus]) IFsm[LampEvent, LampStatus]) {
+func test_Fsm_Clone(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
 	fsm := newFsm(
 		LampStatus_Closed,
 		NewTransition([]Transform[LampEvent, LampStatus]{
@@ -79,7 +79,7 @@ func Test_Fsm_SameState(t *testing.T) {
 	test_Fsm_SameState(t, NewFsm[LampEvent, LampStatus])
 }
 
-func test_Fsm_SameState(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus]) IFsm[LampEvent, LampStatus]) {
+func test_Fsm_SameState(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
 	fsm := newFsm(
 		LampStatus_Closed,
 		NewTransition([]Transform[LampEvent, LampStatus]{
@@ -100,7 +100,7 @@ func Test_Fsm_State(t *testing.T) {
 	test_Fsm_State(t, NewFsm[LampEvent, LampStatus])
 }
 
-func test_Fsm_State(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus]) IFsm[LampEvent, LampStatus]) {
+func test_Fsm_State(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
 	fsm := newFsm(
 		LampStatus_Closed,
 		NewTransition([]Transform[LampEvent, LampStatus]{
@@ -158,7 +158,7 @@ func Test_Fsm_Avail(t *testing.T) {
 	test_Fsm_Avail(t, NewFsm[LampEvent, LampStatus])
 }
 
-func test_Fsm_Avail(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus]) IFsm[LampEvent, LampStatus]) {
+func test_Fsm_Avail(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
 	fsm := newFsm(
 		LampStatus_Closed,
 		NewTransition([]Transform[LampEvent, LampStatus]{
@@ -191,7 +191,7 @@ func Test_Fsm_NonExistEvent_InappropriateEvent(t *testing.T) {
 	test_Fsm_NonExistEvent_InappropriateEvent(t, NewFsm[LampEvent, LampStatus])
 }
 
-func test_Fsm_NonExistEvent_InappropriateEvent(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus]) IFsm[LampEvent, LampStatus]) {
+func test_Fsm_NonExistEvent_InappropriateEvent(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
 	fsm := newFsm(
 		LampStatus_Closed,
 		NewTransition([]Transform[LampEvent, LampStatus]{
@@ -215,7 +215,7 @@ func Test_Fsm_TranslateError(t *testing.T) {
 	test_Fsm_TranslateError(t, NewFsm[LampEvent, LampStatus])
 }
 
-func test_Fsm_TranslateError(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus]) IFsm[LampEvent, LampStatus]) {
+func test_Fsm_TranslateError(t *testing.T, newFsm func(initState LampStatus, ts ITransition[LampEvent, LampStatus], opts ...Option) IFsm[LampEvent, LampStatus]) {
 	fsm := newFsm(
 		LampStatus_Closed,
 		NewTransitionBuilder(
@@ -264,7 +264,7 @@ func Test_Fsm_MultipleSources(t *testing.T) {
 	testFsm_MultipleSources(t, NewSafeFsm[string, string])
 	testFsm_MultipleSources(t, NewFsm[string, string])
 }
-func testFsm_MultipleSources(t *testing.T, newFsm func(initState string, ts ITransition[string, string]) IFsm[string, string]) {
+func testFsm_MultipleSources(t *testing.T, newFsm func(initState string, ts ITransition[string, string], opts ...Option) IFsm[string, string]) {
 	fsm := newFsm(
 		statusOne,
 		NewTransition([]Transform[string, string]{
@@ -311,7 +311,7 @@ func Test_Fsm_MultipleEvents(t *testing.T) {
 	test_Fsm_MultipleEvents(t, NewSafeFsm[string, string])
 	test_Fsm_MultipleEvents(t, NewFsm[string, string])
 }
-func test_Fsm_MultipleEvents(t *testing.T, newFsm func(initState string, ts ITransition[string, string]) IFsm[string, string]) {
+func test_Fsm_MultipleEvents(t *testing.T, newFsm func(initState string, ts ITransition[string, string], opts ...Option) IFsm[string, string]) {
 	fsm := newFsm(
 		statusStart,
 		NewTransition([]Transform[string, string]{
@@ -361,3 +361,169 @@ func test_Fsm_MultipleEvents(t *testing.T, newFsm func(initState string, ts ITra
 		t.Errorf("expected state to be '%s'", statusStart)
 	}
 }
+
+func Test_Fsm_Guard(t *testing.T) {
+	test_Fsm_Guard(t, NewSafeFsm[string, string])
+	test_Fsm_Guard(t, NewFsm[string, string])
+}
+
+func test_Fsm_Guard(t *testing.T, newFsm func(initState string, ts ITransition[string, string], opts ...Option) IFsm[string, string]) {
+	amount := 100
+	small := func(e *Event[string, string]) bool { return amount < 1000 }
+	never := func(e *Event[string, string]) bool { return false }
+	fsm := newFsm(
+		"pending",
+		NewTransition([]Transform[string, string]{
+			{Event: "approve", Src: []string{"pending"}, Dst: "approved", Guard: small, GuardName: "small"},
+			{Event: "approve", Src: []string{"pending"}, Dst: "escalated"},
+			{Event: "reject", Src: []string{"pending"}, Dst: "rejected", Guard: never},
+			{Event: "reset", Src: []string{"approved", "escalated"}, Dst: "pending"},
+		}),
+	)
+
+	if !fsm.MatchCurrentOccur("approve") {
+		t.Error("expected event 'approve' can occur in the current state")
+	}
+	if fsm.MatchCurrentOccur("reject") {
+		t.Error("expected event 'reject' can not occur in the current state")
+	}
+	events := fsm.CurrentAvailEvents()
+	if !slices.Equal(events, []string{"approve"}) {
+		t.Errorf("expected avail events [approve], but got %v", events)
+	}
+	if err := fsm.Trigger("reject"); err != ErrInappropriateEvent {
+		t.Errorf("expected 'ErrInappropriateEvent' with guard rejected, but got %v", err)
+	}
+	if err := fsm.Trigger("approve"); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	if !fsm.Is("approved") {
+		t.Errorf("expected state to be 'approved', but got %s", fsm.Current())
+	}
+	if err := fsm.Trigger("reset"); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	amount = 5000
+	if err := fsm.Trigger("approve"); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	if !fsm.Is("escalated") {
+		t.Errorf("expected state to be 'escalated', but got %s", fsm.Current())
+	}
+	edges := sortedEdges[string, string](fsm)
+	wanted := []Edge[string, string]{
+		{Event: "reset", Src: "approved", Dst: "pending"},
+		{Event: "reset", Src: "escalated", Dst: "pending"},
+		{Event: "approve", Src: "pending", Dst: "approved", Guard: "small"},
+		{Event: "approve", Src: "pending", Dst: "escalated"},
+		{Event: "reject", Src: "pending", Dst: "rejected"},
+	}
+	if !slices.Equal(edges, wanted) {
+		t.Errorf("expected edges %v, but got %v", wanted, edges)
+	}
+}
+
+// customTransition hides the methods of Transition which are not in ITransition.
+type customTransition struct {
+	ITransition[string, string]
+}
+
+func Test_Fsm_CustomTransition(t *testing.T) {
+	test_Fsm_CustomTransition(t, NewSafeFsm[string, string])
+	test_Fsm_CustomTransition(t, NewFsm[string, string])
+}
+
+func test_Fsm_CustomTransition(t *testing.T, newFsm func(initState string, ts ITransition[string, string], opts ...Option) IFsm[string, string]) {
+	small := func(e *Event[string, string]) bool { return e.Args == nil }
+	fsm := newFsm(
+		"pending",
+		customTransition{NewTransition([]Transform[string, string]{
+			{Event: "approve", Src: []string{"pending"}, Dst: "approved", Guard: small, GuardName: "small"},
+			{Event: "approve", Src: []string{"pending"}, Dst: "escalated"},
+			{Event: "reset", Src: []string{"approved", "escalated"}, Dst: "pending"},
+		})},
+	)
+
+	if !fsm.MatchCurrentOccur("approve") {
+		t.Error("expected event 'approve' can occur in the current state")
+	}
+	if events := fsm.CurrentAvailEvents(); !slices.Equal(events, []string{"approve"}) {
+		t.Errorf("expected avail events [approve], but got %v", events)
+	}
+	// the guards only see the event and the src state without the arguments.
+	if err := fsm.Trigger("approve", 5000); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+	if !fsm.Is("approved") {
+		t.Errorf("expected state to be 'approved', but got %s", fsm.Current())
+	}
+	edges := sortedEdges[string, string](fsm)
+	wanted := []Edge[string, string]{
+		{Event: "reset", Src: "approved", Dst: "pending"},
+		{Event: "reset", Src: "escalated", Dst: "pending"},
+		{Event: "approve", Src: "pending", Dst: "approved"},
+	}
+	if !slices.Equal(edges, wanted) {
+		t.Errorf("expected edges %v, but got %v", wanted, edges)
+	}
+}
+
+func Test_Fsm_Guard_Data(t *testing.T) {
+	test_Fsm_Guard_Data(t, NewSafeFsm[string, string])
+	test_Fsm_Guard_Data(t, NewFsm[string, string])
+}
+
+func test_Fsm_Guard_Data(t *testing.T, newFsm func(initState string, ts ITransition[string, string], opts ...Option) IFsm[string, string]) {
+	enough := func(e *Event[string, string]) bool {
+		n, ok := EventData[int](e)
+		return ok && n >= 100
+	}
+	fsm := newFsm(
+		"idle",
+		NewTransition([]Transform[string, string]{
+			{Event: "go", Src: []string{"idle"}, Dst: "running", Guard: enough},
+			{Event: "stop", Src: []string{"running"}, Dst: "idle"},
+		}),
+	)
+
+	if fsm.MatchCurrentOccur("go") || fsm.MatchCurrentAllOccur("go") || len(fsm.CurrentAvailEvents()) != 0 {
+		t.Error("expected event 'go' can not occur without the data")
+	}
+	fsm.SetData(100)
+	if !fsm.MatchCurrentOccur("go") {
+		t.Error("expected event 'go' can occur in the current state")
+	}
+	if !fsm.MatchCurrentAllOccur("go") {
+		t.Error("expected all the events can occur in the current state")
+	}
+	if events := fsm.CurrentAvailEvents(); !slices.Equal(events, []string{"go"}) {
+		t.Errorf("expected avail events [go], but got %v", events)
+	}
+	if err := fsm.Trigger("go"); err != nil {
+		t.Errorf("trigger failed %v", err)
+	}
+}
+
+func Test_SafeFsm_CurrentAvailEvents_Concurrent(t *testing.T) {
+	fsm := NewSafeFsm[string, string](
+		"idle",
+		NewTransition([]Transform[string, string]{
+			{Event: "go", Src: []string{"idle"}, Dst: "running"},
+			{Event: "stop", Src: []string{"running"}, Dst: "idle"},
+		}),
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = fsm.Trigger("go")
+			_ = fsm.Trigger("stop")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_ = fsm.CurrentAvailEvents()
+		_ = fsm.MatchCurrentOccur("go")
+		_ = fsm.MatchCurrentAllOccur("go", "stop")
+	}
+	<-done
+}
@@ -13,6 +13,7 @@ import (
 var (
 	ErrInappropriateEvent = errors.New("fsm: event inappropriate in the state")
 	ErrNonExistEvent      = errors.New("fsm: event does not exist")
+	ErrCanceled           = errors.New("fsm: transition canceled")
 )
 
 type ITransition[E constraints.Ordered, S constraints.Ordered] interface {
@@ -34,8 +35,12 @@ type ITransition[E constraints.Ordered, S constraints.Ordered] interface {
 	ContainsEvent(event E) bool
 	// ContainsAllEvent returns true if support all event.
 	ContainsAllEvent(events ...E) bool
+	// ContainsState returns true if support the state.
+	ContainsState(state S) bool
 	// AvailEvents returns a list of available transform event in src state.
 	AvailEvents(srcState S) []E
+	// IsReversible returns true if any transform of the event from the src state to the dst state is marked reversible.
+	IsReversible(srcState S, event E, dstState S) bool
 	// AvailSourceStates returns a list of available source state in the event.
 	AvailSourceStates(event ...E) []S
 	// SortedTriggerSource return a list of sorted trigger source
@@ -44,12 +49,102 @@ type ITransition[E constraints.Ordered, S constraints.Ordered] interface {
 	SortedStates() []S
 	// SortedEvents return a list of sorted events.
 	SortedEvents() []E
+	// UnreachableStates returns a list of sorted states which can not be reached from the initial state.
+	UnreachableStates(initState S) []S
+	// TerminalStates returns a list of sorted states which have no outgoing transform.
+	TerminalStates() []S
+	// DeadStates returns a list of sorted states which can not reach any of the final states.
+	DeadStates(finalStates ...S) []S
+	// StronglyConnectedComponents returns the strongly connected components of the states.
+	StronglyConnectedComponents() [][]S
+	// PathTo returns the shortest list of events which transforms the from state to the to state.
+	PathTo(from, to S) (events []E, ok bool)
+	// AllPathsTo returns the lists of events which transforms the from state to the to state without visiting a state twice.
+	AllPathsTo(from, to S, limit int) [][]E
 	// StateName returns a event name.
 	EventName(event E) string
 	// StateName returns a state name.
 	StateName(state S) string
 }
 
+// transitionHooks calls the callbacks registered by TransitionBuilder,
+// trigger calls them if the transition implements it.
+type transitionHooks[E constraints.Ordered, S constraints.Ordered] interface {
+	// beforeEvent calls the callbacks registered before the event, it stops at the first error.
+	beforeEvent(e *Event[E, S]) error
+	// leaveState calls the callbacks registered for leaving the src state of the event.
+	leaveState(e *Event[E, S])
+	// enterState calls the callbacks registered for entering the dst state of the event.
+	enterState(e *Event[E, S])
+	// afterEvent calls the callbacks registered after the event.
+	afterEvent(e *Event[E, S])
+}
+
+var _ transitionHooks[string, string] = (*Transition[string, string])(nil)
+
+// noHooks is the hooks of the transition which does not implement transitionHooks.
+type noHooks[E constraints.Ordered, S constraints.Ordered] struct{}
+
+func (noHooks[E, S]) beforeEvent(*Event[E, S]) error { return nil }
+func (noHooks[E, S]) leaveState(*Event[E, S])        {}
+func (noHooks[E, S]) enterState(*Event[E, S])        {}
+func (noHooks[E, S]) afterEvent(*Event[E, S])        {}
+
+// eventTransition evaluates the guards with the event info, Transition implements it,
+// the Fsm uses it if the transition implements it, otherwise the guards see only the event and the src state.
+type eventTransition[E constraints.Ordered, S constraints.Ordered] interface {
+	TransformWith(e *Event[E, S]) (dstState S, err error)
+	MatchOccurWith(e *Event[E, S]) bool
+	AvailEventsWith(e *Event[E, S]) []E
+}
+
+var _ eventTransition[string, string] = (*Transition[string, string])(nil)
+
+func transformWith[E constraints.Ordered, S constraints.Ordered](ts ITransition[E, S], e *Event[E, S]) (S, error) {
+	if et, ok := ts.(eventTransition[E, S]); ok {
+		return et.TransformWith(e)
+	}
+	return ts.Transform(e.Src, e.Event)
+}
+
+func matchOccurWith[E constraints.Ordered, S constraints.Ordered](ts ITransition[E, S], e *Event[E, S]) bool {
+	if et, ok := ts.(eventTransition[E, S]); ok {
+		return et.MatchOccurWith(e)
+	}
+	return ts.MatchOccur(e.Src, e.Event)
+}
+
+func availEventsWith[E constraints.Ordered, S constraints.Ordered](ts ITransition[E, S], e *Event[E, S]) []E {
+	if et, ok := ts.(eventTransition[E, S]); ok {
+		return et.AvailEventsWith(e)
+	}
+	return ts.AvailEvents(e.Src)
+}
+
+// edgeSource is the source of the edges, both ITransition and Visualizer are.
+type edgeSource[E constraints.Ordered, S constraints.Ordered] interface {
+	Transform(srcState S, event E) (dstState S, err error)
+	SortedTriggerSource() []TriggerSource[E, S]
+}
+
+// sortedEdges returns the sorted edges of the source if it implements SortedEdges like Transition,
+// otherwise one edge per sorted trigger source to the dst state which Transform returns.
+func sortedEdges[E constraints.Ordered, S constraints.Ordered](src edgeSource[E, S]) []Edge[E, S] {
+	if es, ok := src.(interface{ SortedEdges() []Edge[E, S] }); ok {
+		return es.SortedEdges()
+	}
+	triggerSources := src.SortedTriggerSource()
+	edges := make([]Edge[E, S], 0, len(triggerSources))
+	for _, ts := range triggerSources {
+		dst, err := src.Transform(ts.src, ts.event)
+		if err != nil {
+			continue
+		}
+		edges = append(edges, Edge[E, S]{Event: ts.event, Src: ts.src, Dst: dst})
+	}
+	return edges
+}
+
 // Transform represents an event when initializing the Fsm.
 //
 // The event can have one or more source states that is valid for performing
@@ -66,6 +161,14 @@ type Transform[E constraints.Ordered, S constraints.Ordered] struct {
 	// Dst is the destination state that the Fsm will be in if the transform
 	// succeeds.
 	Dst S
+	// Guard is the condition of the transform, the transform is skipped if it returns false.
+	// The transforms with the same event and source state are evaluated in declaration order,
+	// the first one without guard or whose guard returns true is taken.
+	Guard Guard[E, S]
+	// GuardName is the name of the guard, the visualizers label the guarded transform with it.
+	GuardName string
+	// Reversible marks the transform can be undone by Undo when the Fsm is constructed with WithUndoReversibleOnly.
+	Reversible bool
 }
 
 // TriggerSource is storing the trigger source.
@@ -82,6 +185,28 @@ func (e *TriggerSource[E, S]) Event() E { return e.event }
 // State the trigger source state.
 func (e *TriggerSource[E, S]) State() S { return e.src }
 
+// Edge is a transform from the source state to the destination state with the event.
+type Edge[E constraints.Ordered, S constraints.Ordered] struct {
+	// Event is the event of the edge.
+	Event E
+	// Src is the source state of the edge.
+	Src S
+	// Dst is the destination state of the edge.
+	Dst S
+	// Guard is the guard name of the edge, empty if the edge is not guarded or the guard has no name.
+	Guard string
+	// Reversible is true if the transform of the edge is marked reversible.
+	Reversible bool
+}
+
+// destination is a candidate destination state of a trigger source.
+type destination[E constraints.Ordered, S constraints.Ordered] struct {
+	dst        S
+	guard      Guard[E, S]
+	guardName  string
+	reversible bool
+}
+
 // Transition contain events and source states to destination states.
 // NOTE: This is immutable
 type Transition[E constraints.Ordered, S constraints.Ordered] struct {
@@ -91,10 +216,22 @@ type Transition[E constraints.Ordered, S constraints.Ordered] struct {
 	events map[E]string
 	// contain all support state and name.
 	states map[S]string
-	// mapping map the trigger source to destination states.
-	mapping map[TriggerSource[E, S]]S
+	// mapping map the trigger source to destination states, in declaration order.
+	mapping map[TriggerSource[E, S]][]destination[E, S]
 	// translate error
 	translate ErrorTranslator
+	// enterStates the callbacks called after entering the state.
+	enterStates map[S][]Callback[E, S]
+	// leaveStates the callbacks called before leaving the state.
+	leaveStates map[S][]Callback[E, S]
+	// beforeEvents the callbacks called before the event.
+	beforeEvents map[E][]BeforeCallback[E, S]
+	// beforeAnyEvent the callbacks called before any event.
+	beforeAnyEvent []BeforeCallback[E, S]
+	// afterEvents the callbacks called after the event.
+	afterEvents map[E][]Callback[E, S]
+	// afterAnyEvent the callbacks called after any event.
+	afterAnyEvent []Callback[E, S]
 }
 
 type TransitionBuilder[E constraints.Ordered, S constraints.Ordered] struct {
@@ -106,11 +243,27 @@ type TransitionBuilder[E constraints.Ordered, S constraints.Ordered] struct {
 	states map[S]string
 	// translate error
 	translate ErrorTranslator
+	// enterStates the callbacks called after entering the state.
+	enterStates map[S][]Callback[E, S]
+	// leaveStates the callbacks called before leaving the state.
+	leaveStates map[S][]Callback[E, S]
+	// beforeEvents the callbacks called before the event.
+	beforeEvents map[E][]BeforeCallback[E, S]
+	// beforeAnyEvent the callbacks called before any event.
+	beforeAnyEvent []BeforeCallback[E, S]
+	// afterEvents the callbacks called after the event.
+	afterEvents map[E][]Callback[E, S]
+	// afterAnyEvent the callbacks called after any event.
+	afterAnyEvent []Callback[E, S]
 }
 
 func NewTransitionBuilder[E constraints.Ordered, S constraints.Ordered](transforms []Transform[E, S]) *TransitionBuilder[E, S] {
 	return &TransitionBuilder[E, S]{
-		transforms: transforms,
+		transforms:   transforms,
+		enterStates:  make(map[S][]Callback[E, S]),
+		leaveStates:  make(map[S][]Callback[E, S]),
+		beforeEvents: make(map[E][]BeforeCallback[E, S]),
+		afterEvents:  make(map[E][]Callback[E, S]),
 	}
 }
 
@@ -129,18 +282,70 @@ func (b *TransitionBuilder[E, S]) TranslatorError(translate ErrorTranslator) *Tr
 	return b
 }
 
+// OnEnter registers the callbacks called after the Fsm entered the state.
+// The callbacks are called in the order of registration.
+func (b *TransitionBuilder[E, S]) OnEnter(state S, fns ...Callback[E, S]) *TransitionBuilder[E, S] {
+	b.enterStates[state] = append(b.enterStates[state], fns...)
+	return b
+}
+
+// OnLeave registers the callbacks called before the Fsm leaves the state.
+// The callbacks are called in the order of registration.
+func (b *TransitionBuilder[E, S]) OnLeave(state S, fns ...Callback[E, S]) *TransitionBuilder[E, S] {
+	b.leaveStates[state] = append(b.leaveStates[state], fns...)
+	return b
+}
+
+// BeforeEvent registers the callbacks called before the event transforms.
+// If one of the callbacks returns an error, the transform is canceled.
+// The callbacks are called in the order of registration.
+func (b *TransitionBuilder[E, S]) BeforeEvent(event E, fns ...BeforeCallback[E, S]) *TransitionBuilder[E, S] {
+	b.beforeEvents[event] = append(b.beforeEvents[event], fns...)
+	return b
+}
+
+// BeforeAnyEvent registers the callbacks called before any event transforms,
+// they are called after the callbacks registered by BeforeEvent.
+// If one of the callbacks returns an error, the transform is canceled.
+func (b *TransitionBuilder[E, S]) BeforeAnyEvent(fns ...BeforeCallback[E, S]) *TransitionBuilder[E, S] {
+	b.beforeAnyEvent = append(b.beforeAnyEvent, fns...)
+	return b
+}
+
+// AfterEvent registers the callbacks called after the event transformed.
+// The callbacks are called in the order of registration.
+func (b *TransitionBuilder[E, S]) AfterEvent(event E, fns ...Callback[E, S]) *TransitionBuilder[E, S] {
+	b.afterEvents[event] = append(b.afterEvents[event], fns...)
+	return b
+}
+
+// AfterAnyEvent registers the callbacks called after any event transformed,
+// they are called after the callbacks registered by AfterEvent.
+func (b *TransitionBuilder[E, S]) AfterAnyEvent(fns ...Callback[E, S]) *TransitionBuilder[E, S] {
+	b.afterAnyEvent = append(b.afterAnyEvent, fns...)
+	return b
+}
+
+// Build builds the transition without validation, the conflicting transforms overwrite the previous ones.
+// Use BuildE to report the problems.
 func (b *TransitionBuilder[E, S]) Build() *Transition[E, S] {
 	t := &Transition[E, S]{
-		name:      b.name,
-		events:    make(map[E]string),
-		states:    make(map[S]string),
-		mapping:   make(map[TriggerSource[E, S]]S),
-		translate: b.translate,
+		name:           b.name,
+		events:         make(map[E]string),
+		states:         make(map[S]string),
+		mapping:        make(map[TriggerSource[E, S]][]destination[E, S]),
+		translate:      b.translate,
+		enterStates:    make(map[S][]Callback[E, S]),
+		leaveStates:    make(map[S][]Callback[E, S]),
+		beforeEvents:   make(map[E][]BeforeCallback[E, S]),
+		beforeAnyEvent: slices.Clone(b.beforeAnyEvent),
+		afterEvents:    make(map[E][]Callback[E, S]),
+		afterAnyEvent:  slices.Clone(b.afterAnyEvent),
 	}
 	for _, ts := range b.transforms {
 		t.events[ts.Event] = ts.Name
 		for _, src := range ts.Src {
-			t.mapping[TriggerSource[E, S]{ts.Event, src}] = ts.Dst
+			t.addDestination(TriggerSource[E, S]{ts.Event, src}, destination[E, S]{ts.Dst, ts.Guard, ts.GuardName, ts.Reversible})
 			t.states[src] = ""
 			t.states[ts.Dst] = ""
 		}
@@ -148,6 +353,18 @@ func (b *TransitionBuilder[E, S]) Build() *Transition[E, S] {
 	for k, v := range b.states {
 		t.states[k] = v
 	}
+	for k, v := range b.enterStates {
+		t.enterStates[k] = slices.Clone(v)
+	}
+	for k, v := range b.leaveStates {
+		t.leaveStates[k] = slices.Clone(v)
+	}
+	for k, v := range b.beforeEvents {
+		t.beforeEvents[k] = slices.Clone(v)
+	}
+	for k, v := range b.afterEvents {
+		t.afterEvents[k] = slices.Clone(v)
+	}
 	return t
 }
 
@@ -166,15 +383,23 @@ func (t *Transition[E, S]) Name() string { return t.name }
 // - ErrInappropriateEvent: event inappropriate in the src state.
 // - ErrNonExistEvent: event does not exist
 func (t *Transition[E, S]) Transform(srcState S, event E) (dstState S, err error) {
-	dstState, ok := t.mapping[TriggerSource[E, S]{event, srcState}]
+	return t.TransformWith(&Event[E, S]{Event: event, Src: srcState})
+}
+
+// TransformWith return the dst state transition with the event info, the guards are evaluated with it.
+// It returns the same errors as Transform, ErrInappropriateEvent if none of the guards passed.
+func (t *Transition[E, S]) TransformWith(e *Event[E, S]) (dstState S, err error) {
+	dsts, ok := t.mapping[TriggerSource[E, S]{e.Event, e.Src}]
 	if !ok {
-		for ts := range t.mapping {
-			if ts.event == event {
-				return dstState, t.translateError(ErrInappropriateEvent)
-			}
+		if t.ContainsEvent(e.Event) {
+			return dstState, t.translateError(ErrInappropriateEvent)
 		}
 		return dstState, t.translateError(ErrNonExistEvent)
 	}
+	dstState, ok = t.matchDestination(e, dsts)
+	if !ok {
+		return dstState, t.translateError(ErrInappropriateEvent)
+	}
 	return dstState, nil
 }
 
@@ -189,13 +414,23 @@ func (t *Transition[E, S]) Match(srcState, dstState S, event E) (bool, error) {
 
 // MatchOccur returns true if event can occur in src state.
 func (t *Transition[E, S]) MatchOccur(srcState S, event E) bool {
-	_, ok := t.mapping[TriggerSource[E, S]{event, srcState}]
+	return t.MatchOccurWith(&Event[E, S]{Event: event, Src: srcState})
+}
+
+// MatchOccurWith returns true if the event can occur in the src state of the event info,
+// the guards are evaluated with it.
+func (t *Transition[E, S]) MatchOccurWith(e *Event[E, S]) bool {
+	dsts, ok := t.mapping[TriggerSource[E, S]{e.Event, e.Src}]
+	if !ok {
+		return false
+	}
+	_, ok = t.matchDestination(e, dsts)
 	return ok
 }
 
 // MatchAllOccur returns true if all the events can occur in src state.
 func (t *Transition[E, S]) MatchAllOccur(srcState S, events ...E) bool {
-	occurEvents := t.availEvents(srcState)
+	occurEvents := t.availEvents(&Event[E, S]{Src: srcState})
 	for _, e := range events {
 		if _, ok := occurEvents[e]; !ok {
 			return false
@@ -221,9 +456,21 @@ func (t *Transition[E, S]) ContainsAllEvent(events ...E) bool {
 	return true
 }
 
+// ContainsState returns true if support the state.
+func (t *Transition[E, S]) ContainsState(state S) bool {
+	_, ok := t.states[state]
+	return ok
+}
+
 // AvailEvents returns a list of available transform event in src state.
 func (t *Transition[E, S]) AvailEvents(srcState S) []E {
-	events := t.availEvents(srcState)
+	return t.AvailEventsWith(&Event[E, S]{Src: srcState})
+}
+
+// AvailEventsWith returns a list of available transform event in the src state of the event info,
+// the guards are evaluated with it, the Event of it is ignored.
+func (t *Transition[E, S]) AvailEventsWith(e *Event[E, S]) []E {
+	events := t.availEvents(e)
 	return maps.Keys(events)
 }
 
@@ -250,6 +497,23 @@ func (t *Transition[E, S]) SortedTriggerSource() []TriggerSource[E, S] {
 	return triggerSources
 }
 
+// SortedEdges return a list of sorted edges, the edges of the same trigger source are in declaration order.
+func (t *Transition[E, S]) SortedEdges() []Edge[E, S] {
+	edges := make([]Edge[E, S], 0, len(t.mapping))
+	for _, ts := range t.SortedTriggerSource() {
+		for _, d := range t.mapping[ts] {
+			edges = append(edges, Edge[E, S]{
+				Event:      ts.event,
+				Src:        ts.src,
+				Dst:        d.dst,
+				Guard:      d.guardName,
+				Reversible: d.reversible,
+			})
+		}
+	}
+	return edges
+}
+
 // SortedStates return a list of sorted states.
 func (t *Transition[E, S]) SortedStates() []S {
 	states := maps.Keys(t.states)
@@ -282,17 +546,95 @@ func (t *Transition[E, S]) StateName(state S) string {
 	return fmt.Sprintf("%v", state)
 }
 
-// availEvents returns an available transform event in src state.
-func (t *Transition[E, S]) availEvents(srcState S) map[E]struct{} {
+// leaveState calls the callbacks registered for leaving the src state of the event.
+func (t *Transition[E, S]) leaveState(e *Event[E, S]) {
+	for _, fn := range t.leaveStates[e.Src] {
+		fn(e)
+	}
+}
+
+// enterState calls the callbacks registered for entering the dst state of the event.
+func (t *Transition[E, S]) enterState(e *Event[E, S]) {
+	for _, fn := range t.enterStates[e.Dst] {
+		fn(e)
+	}
+}
+
+// beforeEvent calls the callbacks registered before the event, it stops at the first error.
+func (t *Transition[E, S]) beforeEvent(e *Event[E, S]) error {
+	for _, fn := range t.beforeEvents[e.Event] {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	for _, fn := range t.beforeAnyEvent {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// afterEvent calls the callbacks registered after the event.
+func (t *Transition[E, S]) afterEvent(e *Event[E, S]) {
+	for _, fn := range t.afterEvents[e.Event] {
+		fn(e)
+	}
+	for _, fn := range t.afterAnyEvent {
+		fn(e)
+	}
+}
+
+// availEvents returns an available transform event in the src state of the event info.
+func (t *Transition[E, S]) availEvents(e *Event[E, S]) map[E]struct{} {
 	occurEvents := make(map[E]struct{})
-	for ts := range t.mapping {
-		if ts.src == srcState {
-			occurEvents[ts.event] = struct{}{}
+	for ts, dsts := range t.mapping {
+		if ts.src == e.Src {
+			probe := *e
+			probe.Event = ts.event
+			if _, ok := t.matchDestination(&probe, dsts); ok {
+				occurEvents[ts.event] = struct{}{}
+			}
 		}
 	}
 	return occurEvents
 }
 
+// addDestination add a candidate destination to the trigger source.
+// The destination without guard overwrites the previous one without guard, so the last one is taken.
+func (t *Transition[E, S]) addDestination(ts TriggerSource[E, S], d destination[E, S]) {
+	dsts := t.mapping[ts]
+	if d.guard == nil {
+		for i := range dsts {
+			if dsts[i].guard == nil {
+				dsts[i] = d
+				return
+			}
+		}
+	}
+	t.mapping[ts] = append(dsts, d)
+}
+
+// IsReversible returns true if any transform of the event from the src state to the dst state is marked reversible.
+func (t *Transition[E, S]) IsReversible(srcState S, event E, dstState S) bool {
+	for _, d := range t.mapping[TriggerSource[E, S]{event, srcState}] {
+		if d.dst == dstState && d.reversible {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDestination returns the first destination state whose guard passed.
+func (t *Transition[E, S]) matchDestination(e *Event[E, S], dsts []destination[E, S]) (dstState S, ok bool) {
+	for _, d := range dsts {
+		if d.guard == nil || d.guard(e) {
+			return d.dst, true
+		}
+	}
+	return dstState, false
+}
+
 // availEvents returns an available transform event in src state.
 func (t *Transition[E, S]) translateError(err error) error {
 	if err == nil || t.translate == nil {
@@ -0,0 +1,154 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+var (
+	ErrNothingToUndo = errors.New("fsm: nothing to undo")
+	ErrNothingToRedo = errors.New("fsm: nothing to redo")
+	ErrIrreversible  = errors.New("fsm: transition is irreversible")
+)
+
+// undoStep is a successful trigger which can be undone.
+type undoStep[E constraints.Ordered, S constraints.Ordered] struct {
+	event E
+	from  S
+	to    S
+	args  []any
+}
+
+// undoStack is the bounded undo and redo stacks of the successful triggers.
+type undoStack[E constraints.Ordered, S constraints.Ordered] struct {
+	depth          int
+	reversibleOnly bool
+	undo           []undoStep[E, S]
+	redo           []undoStep[E, S]
+}
+
+func newUndoStack[E constraints.Ordered, S constraints.Ordered](o *options) *undoStack[E, S] {
+	if !o.undo {
+		return nil
+	}
+	return &undoStack[E, S]{
+		depth:          o.undoDepth,
+		reversibleOnly: o.undoReversibleOnly,
+	}
+}
+
+// push pushes the successful trigger, the oldest one is dropped if the stack is full.
+func (u *undoStack[E, S]) push(step undoStep[E, S]) {
+	u.undo = append(u.undo, step)
+	if u.depth > 0 && len(u.undo) > u.depth {
+		u.undo = append(u.undo[:0:0], u.undo[len(u.undo)-u.depth:]...)
+	}
+}
+
+// recordTrigger pushes the successful trigger and clears the redo stack, it is a no-op if the undo is disabled.
+func (u *undoStack[E, S]) recordTrigger(event E, from, to S, args []any, err error) {
+	if u == nil || err != nil {
+		return
+	}
+	u.push(undoStep[E, S]{event: event, from: from, to: to, args: args})
+	u.redo = nil
+}
+
+// reset clears the stacks, the state is changed without a transition.
+func (u *undoStack[E, S]) reset() {
+	if u == nil {
+		return
+	}
+	u.undo = nil
+	u.redo = nil
+}
+
+// clone returns a copy of the stacks, with the steps if withSteps is true.
+func (u *undoStack[E, S]) clone(withSteps bool) *undoStack[E, S] {
+	if u == nil {
+		return nil
+	}
+	c := &undoStack[E, S]{
+		depth:          u.depth,
+		reversibleOnly: u.reversibleOnly,
+	}
+	if withSteps {
+		c.undo = append([]undoStep[E, S](nil), u.undo...)
+		c.redo = append([]undoStep[E, S](nil), u.redo...)
+	}
+	return c
+}
+
+// undo moves the current state back to the source state of the last successful trigger,
+// the callbacks are called with the Undo event, the guards are not evaluated.
+// It returns the undone event.
+func undo[E constraints.Ordered, S constraints.Ordered](ts ITransition[E, S], current *S, data *any, u *undoStack[E, S], h *history[E, S]) (event E, err error) {
+	if u == nil || len(u.undo) == 0 {
+		return event, ErrNothingToUndo
+	}
+	step := u.undo[len(u.undo)-1]
+	if u.reversibleOnly && !ts.IsReversible(step.from, step.event, step.to) {
+		return event, fmt.Errorf("%w: event %v from %v to %v", ErrIrreversible, step.event, step.from, step.to)
+	}
+	from := *current
+	err = transform(ts, current, &Event[E, S]{
+		Event: step.event,
+		Src:   from,
+		Dst:   step.from,
+		Args:  step.args,
+		Undo:  true,
+		data:  data,
+	})
+	entry := HistoryEntry[E, S]{
+		Kind:  HistoryUndo,
+		Event: step.event,
+		From:  from,
+		To:    *current,
+		Args:  step.args,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	h.record(context.Background(), entry)
+	if err != nil {
+		return event, err
+	}
+	u.undo = u.undo[:len(u.undo)-1]
+	u.redo = append(u.redo, step)
+	return step.event, nil
+}
+
+// redo triggers the event of the last undone step again with its arguments, the guards and callbacks are called.
+// The remaining redo steps are dropped if the destination state differs from the undone one.
+// It returns the redone event.
+func redo[E constraints.Ordered, S constraints.Ordered](ts ITransition[E, S], current *S, data *any, u *undoStack[E, S], h *history[E, S]) (event E, err error) {
+	if u == nil || len(u.redo) == 0 {
+		return event, ErrNothingToRedo
+	}
+	step := u.redo[len(u.redo)-1]
+	from := *current
+	err = trigger(context.Background(), ts, current, data, step.event, step.args...)
+	entry := HistoryEntry[E, S]{
+		Kind:  HistoryRedo,
+		Event: step.event,
+		From:  from,
+		To:    *current,
+		Args:  step.args,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	h.record(context.Background(), entry)
+	if err != nil {
+		return event, err
+	}
+	u.redo = u.redo[:len(u.redo)-1]
+	u.push(undoStep[E, S]{event: step.event, from: from, to: *current, args: step.args})
+	if *current != step.to {
+		u.redo = nil
+	}
+	return step.event, nil
+}
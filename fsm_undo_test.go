@@ -0,0 +1,213 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func newTestUndoTransition(allow *bool) *Transition[string, string] {
+	return NewTransitionBuilder([]Transform[string, string]{
+		{Event: "submit", Src: []string{"draft"}, Dst: "review", Reversible: true},
+		{Event: "publish", Src: []string{"review"}, Dst: "published", Guard: func(*Event[string, string]) bool { return *allow }},
+		{Event: "archive", Src: []string{"published"}, Dst: "archived", Reversible: true},
+	}).
+		Build()
+}
+
+func Test_Fsm_Undo(t *testing.T) {
+	test_Fsm_Undo(t, NewSafeFsm[string, string])
+	test_Fsm_Undo(t, NewFsm[string, string])
+}
+
+func test_Fsm_Undo(t *testing.T, newFsm func(initState string, ts ITransition[string, string], opts ...Option) IFsm[string, string]) {
+	allow := true
+	ts := newTestUndoTransition(&allow)
+	mustTrigger := func(t *testing.T, fsm IFsm[string, string], events ...string) {
+		t.Helper()
+		for _, event := range events {
+			if err := fsm.Trigger(event); err != nil {
+				t.Fatalf("trigger %s failed %v", event, err)
+			}
+		}
+	}
+	expectState := func(t *testing.T, fsm IFsm[string, string], state string) {
+		t.Helper()
+		if !fsm.Is(state) {
+			t.Errorf("expected state to be %q, but got %q", state, fsm.Current())
+		}
+	}
+
+	t.Run("undo redo", func(t *testing.T) {
+		fsm := newFsm("draft", ts, WithUndo(0))
+		mustTrigger(t, fsm, "submit", "publish")
+		for _, state := range []string{"review", "draft"} {
+			if err := fsm.Undo(); err != nil {
+				t.Fatalf("undo failed %v", err)
+			}
+			expectState(t, fsm, state)
+		}
+		if err := fsm.Undo(); !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("expected ErrNothingToUndo, but got %v", err)
+		}
+		for _, state := range []string{"review", "published"} {
+			if err := fsm.Redo(); err != nil {
+				t.Fatalf("redo failed %v", err)
+			}
+			expectState(t, fsm, state)
+		}
+		if err := fsm.Redo(); !errors.Is(err, ErrNothingToRedo) {
+			t.Errorf("expected ErrNothingToRedo, but got %v", err)
+		}
+	})
+	t.Run("depth", func(t *testing.T) {
+		fsm := newFsm("draft", ts, WithUndo(2))
+		mustTrigger(t, fsm, "submit", "publish", "archive")
+		_ = fsm.Undo()
+		_ = fsm.Undo()
+		if err := fsm.Undo(); !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("expected ErrNothingToUndo, but got %v", err)
+		}
+		expectState(t, fsm, "review")
+	})
+	t.Run("trigger clears redo", func(t *testing.T) {
+		fsm := newFsm("draft", ts, WithUndo(0))
+		mustTrigger(t, fsm, "submit")
+		_ = fsm.Undo()
+		mustTrigger(t, fsm, "submit")
+		if err := fsm.Redo(); !errors.Is(err, ErrNothingToRedo) {
+			t.Errorf("expected ErrNothingToRedo, but got %v", err)
+		}
+	})
+	t.Run("set current clears undo", func(t *testing.T) {
+		fsm := newFsm("draft", ts, WithUndo(0))
+		mustTrigger(t, fsm, "submit")
+		fsm.SetCurrent("published")
+		if err := fsm.Undo(); !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("expected ErrNothingToUndo, but got %v", err)
+		}
+	})
+	t.Run("reversible only", func(t *testing.T) {
+		fsm := newFsm("draft", ts, WithUndoReversibleOnly())
+		mustTrigger(t, fsm, "submit", "publish")
+		if err := fsm.Undo(); !errors.Is(err, ErrIrreversible) {
+			t.Errorf("expected ErrIrreversible, but got %v", err)
+		}
+		expectState(t, fsm, "published")
+		mustTrigger(t, fsm, "archive")
+		if err := fsm.Undo(); err != nil {
+			t.Errorf("undo failed %v", err)
+		}
+		expectState(t, fsm, "published")
+	})
+	t.Run("redo evaluates guards", func(t *testing.T) {
+		fsm := newFsm("review", ts, WithUndo(0), WithHistory(0), WithClock(newTestClock()))
+		mustTrigger(t, fsm, "publish")
+		_ = fsm.Undo()
+		allow = false
+		defer func() { allow = true }()
+		if err := fsm.Redo(); !errors.Is(err, ErrInappropriateEvent) {
+			t.Errorf("expected ErrInappropriateEvent, but got %v", err)
+		}
+		expectState(t, fsm, "review")
+		allow = true
+		if err := fsm.Redo(); err != nil {
+			t.Errorf("redo failed %v", err)
+		}
+		expectState(t, fsm, "published")
+
+		kinds := make([]HistoryKind, 0)
+		for _, entry := range fsm.History() {
+			kinds = append(kinds, entry.Kind)
+		}
+		want := []HistoryKind{HistoryTrigger, HistoryUndo, HistoryRedo, HistoryRedo}
+		if !reflect.DeepEqual(kinds, want) {
+			t.Errorf("history kinds = %v, wanted %v", kinds, want)
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		fsm := newFsm("draft", ts)
+		mustTrigger(t, fsm, "submit")
+		if err := fsm.Undo(); !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("expected ErrNothingToUndo, but got %v", err)
+		}
+	})
+}
+
+func Test_Fsm_Undo_Callbacks(t *testing.T) {
+	test_Fsm_Undo_Callbacks(t, NewSafeFsm[string, string])
+	test_Fsm_Undo_Callbacks(t, NewFsm[string, string])
+}
+
+func test_Fsm_Undo_Callbacks(t *testing.T, newFsm func(initState string, ts ITransition[string, string], opts ...Option) IFsm[string, string]) {
+	var calls []string
+	veto := false
+	record := func(name string) Callback[string, string] {
+		return func(e *Event[string, string]) {
+			calls = append(calls, fmt.Sprintf("%s %s %s->%s undo=%v", name, e.Event, e.Src, e.Dst, e.Undo))
+		}
+	}
+	ts := NewTransitionBuilder([]Transform[string, string]{
+		{Event: "submit", Src: []string{"draft"}, Dst: "review"},
+	}).
+		BeforeEvent("submit", func(e *Event[string, string]) error {
+			if e.Undo && veto {
+				return errors.New("published review")
+			}
+			return nil
+		}).
+		OnLeave("review", record("leave")).
+		OnEnter("draft", record("enter")).
+		AfterEvent("submit", record("after")).
+		Build()
+
+	fsm := newFsm("draft", ts, WithUndo(0), WithHistory(0), WithClock(newTestClock()))
+	if err := fsm.Trigger("submit"); err != nil {
+		t.Fatalf("trigger submit failed %v", err)
+	}
+	calls = nil
+	veto = true
+	if err := fsm.Undo(); !errors.Is(err, ErrCanceled) {
+		t.Errorf("expected ErrCanceled, but got %v", err)
+	}
+	if !fsm.Is("review") || len(calls) != 0 {
+		t.Errorf("expected the undo to be canceled in review, but got %q with the calls %q", fsm.Current(), calls)
+	}
+	veto = false
+	if err := fsm.Undo(); err != nil {
+		t.Fatalf("undo failed %v", err)
+	}
+	want := []string{
+		"leave submit review->draft undo=true",
+		"enter submit review->draft undo=true",
+		"after submit review->draft undo=true",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, wanted %q", calls, want)
+	}
+	kinds := make([]HistoryKind, 0)
+	for _, entry := range fsm.History() {
+		kinds = append(kinds, entry.Kind)
+	}
+	if wantKinds := []HistoryKind{HistoryTrigger, HistoryUndo, HistoryUndo}; !reflect.DeepEqual(kinds, wantKinds) {
+		t.Errorf("history kinds = %v, wanted %v", kinds, wantKinds)
+	}
+	if err := fsm.Redo(); err != nil || !fsm.Is("review") {
+		t.Errorf("redo failed %v in %q", err, fsm.Current())
+	}
+}
+
+func Test_Transition_IsReversible(t *testing.T) {
+	allow := true
+	ts := newTestUndoTransition(&allow)
+	if !ts.IsReversible("draft", "submit", "review") {
+		t.Errorf("expected submit from draft to review to be reversible")
+	}
+	if ts.IsReversible("review", "publish", "published") {
+		t.Errorf("expected publish from review to published to be irreversible")
+	}
+	if ts.IsReversible("draft", "submit", "published") {
+		t.Errorf("expected no transform of submit from draft to published")
+	}
+}
@@ -1,6 +1,11 @@
 package fsm
 
-import "golang.org/x/exp/constraints"
+import (
+	"context"
+
+	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
+)
 
 var _ IFsm[string, string] = (*Fsm[string, string])(nil)
 var _ IFsm[int, string] = (*Fsm[int, string])(nil)
@@ -14,49 +19,113 @@ type Fsm[E constraints.Ordered, S constraints.Ordered] struct {
 	ITransition[E, S]
 	// current is the state that the Fsm is currently in.
 	current S
+	// data is the data attached to the Fsm.
+	data any
+	// history records the state changes, nil if it is disabled.
+	history *history[E, S]
+	// undo is the stacks of Undo and Redo, nil if it is disabled.
+	undo *undoStack[E, S]
 }
 
-// NewFsm constructs a generic Fsm with an initial state S and a transition.
+// NewFsm constructs a generic Fsm with an initial state S, a transition and the options.
 // E is the event type
 // S is the state type.
-func NewFsm[E constraints.Ordered, S constraints.Ordered](initState S, ts ITransition[E, S]) IFsm[E, S] {
+func NewFsm[E constraints.Ordered, S constraints.Ordered](initState S, ts ITransition[E, S], opts ...Option) IFsm[E, S] {
+	o := newOptions(opts...)
 	return &Fsm[E, S]{
 		current:     initState,
 		ITransition: ts,
+		history:     newHistory[E, S](o),
+		undo:        newUndoStack[E, S](o),
 	}
 }
 func (f *Fsm[E, S]) Clone() IFsm[E, S] {
 	return &Fsm[E, S]{
 		current:     f.current,
+		data:        cloneData(f.data),
 		ITransition: f.ITransition,
+		history:     f.history.clone(true),
+		undo:        f.undo.clone(true),
 	}
 }
 func (f *Fsm[E, S]) CloneNewState(newState S) IFsm[E, S] {
 	return &Fsm[E, S]{
 		current:     newState,
+		data:        cloneData(f.data),
 		ITransition: f.ITransition,
+		history:     f.history.clone(false),
+		undo:        f.undo.clone(false),
 	}
 }
-func (f *Fsm[E, S]) Current() S         { return f.current }
-func (f *Fsm[E, S]) Is(state S) bool    { return state == f.current }
-func (f *Fsm[E, S]) SetCurrent(state S) { f.current = state }
-func (f *Fsm[E, S]) Trigger(event E) error {
-	dst, err := f.ITransition.Transform(f.current, event)
-	if err != nil {
+func (f *Fsm[E, S]) Current() S      { return f.current }
+func (f *Fsm[E, S]) Is(state S) bool { return state == f.current }
+func (f *Fsm[E, S]) SetCurrent(state S) {
+	f.history.recordSetCurrent(f.current, state)
+	f.undo.reset()
+	f.current = state
+}
+func (f *Fsm[E, S]) Data() any        { return f.data }
+func (f *Fsm[E, S]) SetData(data any) { f.data = data }
+func (f *Fsm[E, S]) UpdateData(fn func(data any) any) {
+	f.data = fn(f.data)
+}
+func (f *Fsm[E, S]) Trigger(event E, args ...any) error {
+	return f.TriggerContext(context.Background(), event, args...)
+}
+func (f *Fsm[E, S]) TriggerContext(ctx context.Context, event E, args ...any) error {
+	from := f.current
+	err := trigger(ctx, f.ITransition, &f.current, &f.data, event, args...)
+	f.history.recordTrigger(ctx, event, from, f.current, args, err)
+	f.undo.recordTrigger(event, from, f.current, args, err)
+	return err
+}
+func (f *Fsm[E, S]) Undo() error {
+	_, err := undo(f.ITransition, &f.current, &f.data, f.undo, f.history)
+	return err
+}
+func (f *Fsm[E, S]) Redo() error {
+	_, err := redo(f.ITransition, &f.current, &f.data, f.undo, f.history)
+	return err
+}
+func (f *Fsm[E, S]) History() []HistoryEntry[E, S] {
+	return f.history.list()
+}
+func (f *Fsm[E, S]) Snapshot() Snapshot[E, S] {
+	return newSnapshot(f.ITransition, f.current, f.data, f.history.list())
+}
+func (f *Fsm[E, S]) Restore(snapshot Snapshot[E, S]) error {
+	if err := verifySnapshot(f.ITransition, snapshot); err != nil {
 		return err
 	}
-	f.current = dst
+	f.current = snapshot.State
+	f.data = cloneData(snapshot.Data)
+	f.history.restore(snapshot.History)
+	f.undo.reset()
 	return nil
 }
 func (f *Fsm[E, S]) MatchCurrentOccur(event E) bool {
-	return f.ITransition.MatchOccur(f.current, event)
+	return matchOccurWith(f.ITransition, probeEvent(event, f.current, f.data))
 }
-func (f *Fsm[E, S]) MatchCurrentAllOccur(event ...E) bool {
-	return f.ITransition.MatchAllOccur(f.current, event...)
+func (f *Fsm[E, S]) MatchCurrentAllOccur(events ...E) bool {
+	var zero E
+	occurEvents := availEventsWith(f.ITransition, probeEvent(zero, f.current, f.data))
+	for _, event := range events {
+		if !slices.Contains(occurEvents, event) {
+			return false
+		}
+	}
+	return true
 }
 func (f *Fsm[E, S]) CurrentAvailEvents() []E {
-	return f.ITransition.AvailEvents(f.current)
+	var zero E
+	return availEventsWith(f.ITransition, probeEvent(zero, f.current, f.data))
+}
+func (f *Fsm[E, S]) SortedEdges() []Edge[E, S] {
+	return sortedEdges[E, S](f.ITransition)
 }
 func (f *Fsm[E, S]) Visualize(t VisualizeType) (string, error) {
 	return Visualize[E, S](t, f)
 }
+func (f *Fsm[E, S]) VisualizeWithOptions(t VisualizeType, opts ...VisualizeOption) (string, error) {
+	return VisualizeWithOptions[E, S](t, f, opts...)
+}
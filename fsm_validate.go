@@ -0,0 +1,132 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// ValidateCode is the code of a problem found by validating the transition.
+type ValidateCode string
+
+const (
+	// ValidateEmptySource the transform has no source state.
+	ValidateEmptySource ValidateCode = "empty-source"
+	// ValidateDuplicateTrigger the transforms without guard have the same event and source state and the same destination state.
+	ValidateDuplicateTrigger ValidateCode = "duplicate-trigger"
+	// ValidateConflictTrigger the transforms without guard have the same event and source state but different destination states.
+	ValidateConflictTrigger ValidateCode = "conflict-trigger"
+	// ValidateShadowedTrigger the guarded transform is declared after a transform without guard of the same event and source state,
+	// so it is never taken.
+	ValidateShadowedTrigger ValidateCode = "shadowed-trigger"
+	// ValidateInconsistentEventName the transforms of the same event have different names.
+	ValidateInconsistentEventName ValidateCode = "inconsistent-event-name"
+	// ValidateUnknownState the state name is given for a state which is not used by any transform.
+	ValidateUnknownState ValidateCode = "unknown-state"
+)
+
+// ValidateError is a problem found by validating the transition.
+type ValidateError struct {
+	// Code is the code of the problem.
+	Code ValidateCode
+	// Transforms are the indexes of the transforms which have the problem, empty if it is not related to transforms.
+	Transforms []int
+	// Msg describes the problem.
+	Msg string
+}
+
+func (e *ValidateError) Error() string { return e.Msg }
+
+// ValidateErrors is the list of all problems found by validating the transition.
+type ValidateErrors []*ValidateError
+
+func (e ValidateErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Msg)
+	}
+	return "fsm: invalid transition: " + strings.Join(msgs, "; ")
+}
+
+// Validate reports all problems of the transforms and the state names,
+// it returns nil or ValidateErrors.
+func (b *TransitionBuilder[E, S]) Validate() error {
+	var errs ValidateErrors
+
+	addErr := func(code ValidateCode, transforms []int, format string, a ...any) {
+		errs = append(errs, &ValidateError{
+			Code:       code,
+			Transforms: transforms,
+			Msg:        fmt.Sprintf(format, a...),
+		})
+	}
+	eventIndex := make(map[E]int)
+	states := make(map[S]struct{})
+	triggerSources := make(map[TriggerSource[E, S]][]int)
+	sortedTriggerSources := make([]TriggerSource[E, S], 0, len(b.transforms))
+	for i, ts := range b.transforms {
+		if len(ts.Src) == 0 {
+			addErr(ValidateEmptySource, []int{i}, "transform #%d of event %v has no source state", i, ts.Event)
+		}
+		if j, ok := eventIndex[ts.Event]; !ok {
+			eventIndex[ts.Event] = i
+		} else if name := b.transforms[j].Name; name != ts.Name {
+			addErr(ValidateInconsistentEventName, []int{j, i}, "transforms #%d and #%d of event %v have different names %q and %q", j, i, ts.Event, name, ts.Name)
+		}
+		states[ts.Dst] = struct{}{}
+		for _, src := range ts.Src {
+			states[src] = struct{}{}
+			key := TriggerSource[E, S]{ts.Event, src}
+			if _, ok := triggerSources[key]; !ok {
+				sortedTriggerSources = append(sortedTriggerSources, key)
+			}
+			triggerSources[key] = append(triggerSources[key], i)
+		}
+	}
+	for _, key := range sortedTriggerSources {
+		unguarded := -1
+		for _, i := range triggerSources[key] {
+			ts := b.transforms[i]
+			switch {
+			case unguarded == -1:
+				if ts.Guard == nil {
+					unguarded = i
+				}
+			case ts.Guard != nil:
+				addErr(ValidateShadowedTrigger, []int{unguarded, i}, "transform #%d of event %v from state %v is shadowed by transform #%d without guard", i, key.event, key.src, unguarded)
+			case ts.Dst == b.transforms[unguarded].Dst:
+				addErr(ValidateDuplicateTrigger, []int{unguarded, i}, "transforms #%d and #%d of event %v from state %v are duplicated", unguarded, i, key.event, key.src)
+			default:
+				addErr(ValidateConflictTrigger, []int{unguarded, i}, "transforms #%d and #%d of event %v from state %v conflict with destination states %v and %v", unguarded, i, key.event, key.src, b.transforms[unguarded].Dst, ts.Dst)
+			}
+		}
+	}
+	for _, state := range sortedKeys(b.states) {
+		if _, ok := states[state]; !ok {
+			addErr(ValidateUnknownState, nil, "state name %q is given for unknown state %v", b.states[state], state)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// BuildE validates the transforms and the state names then builds the transition,
+// it returns ValidateErrors if there are problems.
+func (b *TransitionBuilder[E, S]) BuildE() (*Transition[E, S], error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return b.Build(), nil
+}
+
+// sortedKeys returns the sorted keys of the map.
+func sortedKeys[K constraints.Ordered, V any](m map[K]V) []K {
+	keys := maps.Keys(m)
+	slices.Sort(keys)
+	return keys
+}
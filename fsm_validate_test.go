@@ -0,0 +1,65 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func Test_TransitionBuilder_Validate(t *testing.T) {
+	always := func(e *Event[string, string]) bool { return true }
+	_, err := NewTransitionBuilder([]Transform[string, string]{
+		{Name: "Open", Event: "open", Src: []string{"closed"}, Dst: "opened"},
+		{Name: "Close", Event: "close", Src: []string{"opened"}, Dst: "closed"},
+		{Name: "Open", Event: "open", Src: []string{"closed", "broken"}, Dst: "half"},
+		{Name: "Close", Event: "close", Src: []string{"opened"}, Dst: "closed"},
+		{Name: "Fix", Event: "fix", Src: []string{}, Dst: "closed"},
+		{Name: "OPEN", Event: "open", Src: []string{"closed"}, Dst: "opened", Guard: always},
+	}).
+		StateNames(map[string]string{
+			"closed":  "Closed",
+			"missing": "Missing",
+		}).
+		BuildE()
+	var errs ValidateErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected 'ValidateErrors', but got %v", err)
+	}
+	type problem struct {
+		code       ValidateCode
+		transforms []int
+	}
+	wanted := []problem{
+		{ValidateEmptySource, []int{4}},
+		{ValidateInconsistentEventName, []int{0, 5}},
+		{ValidateConflictTrigger, []int{0, 2}},
+		{ValidateShadowedTrigger, []int{0, 5}},
+		{ValidateDuplicateTrigger, []int{1, 3}},
+		{ValidateUnknownState, nil},
+	}
+	if len(errs) != len(wanted) {
+		t.Fatalf("expected %d problems, but got %d: %v", len(wanted), len(errs), errs)
+	}
+	for i, w := range wanted {
+		if errs[i].Code != w.code || !slices.Equal(errs[i].Transforms, w.transforms) {
+			t.Errorf("expected problem %v %v, but got %v %v: %s", w.code, w.transforms, errs[i].Code, errs[i].Transforms, errs[i].Msg)
+		}
+	}
+}
+
+func Test_TransitionBuilder_BuildE(t *testing.T) {
+	ts, err := NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+		{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened, Guard: func(e *Event[LampEvent, LampStatus]) bool { return true }},
+		{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Intermediate},
+		{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened, LampStatus_Intermediate}, Dst: LampStatus_Closed},
+	}).
+		StateNames(map[LampStatus]string{LampStatus_Closed: "Closed"}).
+		BuildE()
+	if err != nil {
+		t.Fatalf("expected build no error, but got %v", err)
+	}
+	if !slices.Equal(ts.SortedStates(), []LampStatus{LampStatus_Closed, LampStatus_Intermediate, LampStatus_Opened}) {
+		t.Errorf("expected states [closed intermediate opened], but got %v", ts.SortedStates())
+	}
+}
@@ -3,3 +3,5 @@ module github.com/things-go/fsm
 go 1.20
 
 require golang.org/x/exp v0.0.0-20230626212559-97b1e661b5df
+
+require gopkg.in/yaml.v3 v3.0.1
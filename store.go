@@ -0,0 +1,117 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/exp/constraints"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("fsm: snapshot not found")
+	ErrVersionConflict  = errors.New("fsm: snapshot version conflict")
+)
+
+// Store persists the snapshots of the machines keyed by machine id with a version number.
+type Store[E constraints.Ordered, S constraints.Ordered] interface {
+	// Load returns the snapshot of the machine and its version.
+	// It returns ErrSnapshotNotFound if the machine does not exist.
+	Load(ctx context.Context, id string) (snapshot Snapshot[E, S], version int64, err error)
+	// Save saves the snapshot of the machine with version+1 if the stored version equals the version,
+	// the version 0 means the machine does not exist yet.
+	// It returns ErrVersionConflict if the version mismatch.
+	Save(ctx context.Context, id string, snapshot Snapshot[E, S], version int64) error
+}
+
+var _ Store[string, string] = (*MemoryStore[string, string])(nil)
+
+// MemoryStore is the in-memory Store.
+type MemoryStore[E constraints.Ordered, S constraints.Ordered] struct {
+	mu      sync.RWMutex
+	records map[string]memoryRecord[E, S]
+}
+
+type memoryRecord[E constraints.Ordered, S constraints.Ordered] struct {
+	snapshot Snapshot[E, S]
+	version  int64
+}
+
+// NewMemoryStore new a in-memory Store instance.
+func NewMemoryStore[E constraints.Ordered, S constraints.Ordered]() *MemoryStore[E, S] {
+	return &MemoryStore[E, S]{
+		records: make(map[string]memoryRecord[E, S]),
+	}
+}
+
+// Load returns the snapshot of the machine and its version.
+func (s *MemoryStore[E, S]) Load(_ context.Context, id string) (Snapshot[E, S], int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.records[id]
+	if !ok {
+		return Snapshot[E, S]{}, 0, ErrSnapshotNotFound
+	}
+	return r.snapshot, r.version, nil
+}
+
+// Save saves the snapshot of the machine with version+1 if the stored version equals the version.
+func (s *MemoryStore[E, S]) Save(_ context.Context, id string, snapshot Snapshot[E, S], version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.records[id].version != version {
+		return ErrVersionConflict
+	}
+	s.records[id] = memoryRecord[E, S]{snapshot, version + 1}
+	return nil
+}
+
+// PersistentFsm triggers the machines persisted in the Store.
+// Each trigger loads the machine, triggers the event and saves it with compare-and-swap semantics on the version.
+type PersistentFsm[E constraints.Ordered, S constraints.Ordered] struct {
+	// fsm is the prototype of the machines, its current state is the initial state of a new machine.
+	fsm   IFsm[E, S]
+	store Store[E, S]
+}
+
+// NewPersistentFsm new a PersistentFsm instance, the fsm is the prototype of the machines,
+// its current state and data are the initial ones of the machine which does not exist in the store.
+func NewPersistentFsm[E constraints.Ordered, S constraints.Ordered](fsm IFsm[E, S], store Store[E, S]) *PersistentFsm[E, S] {
+	return &PersistentFsm[E, S]{
+		fsm:   fsm,
+		store: store,
+	}
+}
+
+// Load returns the machine of the id and its version, the version is 0 if the machine does not exist.
+func (p *PersistentFsm[E, S]) Load(ctx context.Context, id string) (IFsm[E, S], int64, error) {
+	fsm := p.fsm.Clone()
+	snapshot, version, err := p.store.Load(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrSnapshotNotFound) {
+			return fsm, 0, nil
+		}
+		return nil, 0, err
+	}
+	if err = fsm.Restore(snapshot); err != nil {
+		return nil, 0, err
+	}
+	return fsm, version, nil
+}
+
+// Trigger loads the machine of the id, triggers the event and saves it with the loaded version.
+// It returns the machine after the event triggered, or the error of Trigger, or ErrVersionConflict
+// if the machine is saved by others meanwhile.
+func (p *PersistentFsm[E, S]) Trigger(ctx context.Context, id string, event E, args ...any) (IFsm[E, S], error) {
+	fsm, version, err := p.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = fsm.TriggerContext(ctx, event, args...); err != nil {
+		return nil, err
+	}
+	if err = p.store.Save(ctx, id, fsm.Snapshot(), version); err != nil {
+		return nil, err
+	}
+	return fsm, nil
+}
@@ -0,0 +1,111 @@
+package fsm
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/exp/constraints"
+)
+
+var _ Store[string, string] = (*SQLStore[string, string])(nil)
+
+// SQLStore is the Store with database/sql, the snapshot is encoded as JSON.
+// The table must have the columns:
+//
+//	id       VARCHAR PRIMARY KEY
+//	version  BIGINT NOT NULL
+//	snapshot TEXT NOT NULL
+type SQLStore[E constraints.Ordered, S constraints.Ordered] struct {
+	db          *sql.DB
+	table       string
+	placeholder func(n int) string
+	newData     func() any
+}
+
+// NewSQLStore new a SQLStore instance with the table, it uses the question placeholder by default.
+func NewSQLStore[E constraints.Ordered, S constraints.Ordered](db *sql.DB, table string) *SQLStore[E, S] {
+	return &SQLStore[E, S]{
+		db:          db,
+		table:       table,
+		placeholder: QuestionPlaceholder,
+	}
+}
+
+// QuestionPlaceholder returns the placeholder "?", used by MySQL and SQLite.
+func QuestionPlaceholder(int) string { return "?" }
+
+// DollarPlaceholder returns the placeholder "$n", used by PostgreSQL.
+func DollarPlaceholder(n int) string { return "$" + strconv.Itoa(n) }
+
+// Placeholder sets the placeholder of the n-th (start at 1) argument of the statements.
+func (s *SQLStore[E, S]) Placeholder(placeholder func(n int) string) *SQLStore[E, S] {
+	s.placeholder = placeholder
+	return s
+}
+
+// NewData sets the function which returns a pointer of the concrete data type,
+// the data of the snapshot is decoded into it, so the loaded data is that pointer, see Snapshot.
+func (s *SQLStore[E, S]) NewData(newData func() any) *SQLStore[E, S] {
+	s.newData = newData
+	return s
+}
+
+// Load returns the snapshot of the machine and its version.
+func (s *SQLStore[E, S]) Load(ctx context.Context, id string) (snapshot Snapshot[E, S], version int64, err error) {
+	var b []byte
+
+	query := fmt.Sprintf("SELECT version, snapshot FROM %s WHERE id = %s", s.table, s.placeholder(1))
+	err = s.db.QueryRowContext(ctx, query, id).Scan(&version, &b)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return snapshot, 0, ErrSnapshotNotFound
+		}
+		return snapshot, 0, err
+	}
+	if s.newData != nil {
+		snapshot.Data = s.newData()
+	}
+	if err = json.Unmarshal(b, &snapshot); err != nil {
+		return snapshot, 0, err
+	}
+	return snapshot, version, nil
+}
+
+// Save saves the snapshot of the machine with version+1 if the stored version equals the version.
+func (s *SQLStore[E, S]) Save(ctx context.Context, id string, snapshot Snapshot[E, S], version int64) error {
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		query := fmt.Sprintf("INSERT INTO %s (id, version, snapshot) VALUES (%s, %s, %s)",
+			s.table, s.placeholder(1), s.placeholder(2), s.placeholder(3))
+		_, err = s.db.ExecContext(ctx, query, id, version+1, string(b))
+		if err != nil {
+			// the insert fails with a driver specific error if the machine exists.
+			if _, _, loadErr := s.Load(ctx, id); loadErr == nil {
+				return ErrVersionConflict
+			}
+			return err
+		}
+		return nil
+	}
+	query := fmt.Sprintf("UPDATE %s SET version = %s, snapshot = %s WHERE id = %s AND version = %s",
+		s.table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4))
+	result, err := s.db.ExecContext(ctx, query, version+1, string(b), id, version)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
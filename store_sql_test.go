@@ -0,0 +1,100 @@
+package fsm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testDriver is a database/sql driver and connector which only understands the statements of SQLStore,
+// each test opens the database with its own one by sql.OpenDB, so the rows are not shared.
+type testDriver struct {
+	mu   sync.Mutex
+	rows map[string]testRow
+}
+
+type testRow struct {
+	version  int64
+	snapshot string
+}
+
+func (d *testDriver) Open(string) (driver.Conn, error)             { return &testConn{d}, nil }
+func (d *testDriver) Connect(context.Context) (driver.Conn, error) { return &testConn{d}, nil }
+func (d *testDriver) Driver() driver.Driver                        { return d }
+
+type testConn struct{ d *testDriver }
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return &testStmt{c.d, query}, nil
+}
+func (c *testConn) Close() error              { return nil }
+func (c *testConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type testStmt struct {
+	d     *testDriver
+	query string
+}
+
+func (s *testStmt) Close() error  { return nil }
+func (s *testStmt) NumInput() int { return -1 }
+
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	switch {
+	case strings.HasPrefix(s.query, "INSERT INTO machines (id, version, snapshot) VALUES ($1, $2, $3)"):
+		id := args[0].(string)
+		if _, ok := s.d.rows[id]; ok {
+			return nil, errors.New("duplicate key")
+		}
+		s.d.rows[id] = testRow{args[1].(int64), args[2].(string)}
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "UPDATE machines SET version = $1, snapshot = $2 WHERE id = $3 AND version = $4"):
+		id := args[2].(string)
+		if r, ok := s.d.rows[id]; !ok || r.version != args[3].(int64) {
+			return driver.RowsAffected(0), nil
+		}
+		s.d.rows[id] = testRow{args[0].(int64), args[1].(string)}
+		return driver.RowsAffected(1), nil
+	}
+	return nil, errors.New("unknown statement: " + s.query)
+}
+
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	if !strings.HasPrefix(s.query, "SELECT version, snapshot FROM machines WHERE id = $1") {
+		return nil, errors.New("unknown statement: " + s.query)
+	}
+	rows := &testRows{}
+	if r, ok := s.d.rows[args[0].(string)]; ok {
+		rows.values = append(rows.values, []driver.Value{r.version, []byte(r.snapshot)})
+	}
+	return rows, nil
+}
+
+type testRows struct {
+	values [][]driver.Value
+}
+
+func (r *testRows) Columns() []string { return []string{"version", "snapshot"} }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func Test_SQLStore(t *testing.T) {
+	db := sql.OpenDB(&testDriver{rows: make(map[string]testRow)})
+	defer db.Close()
+	test_Store(t, NewSQLStore[LampEvent, LampStatus](db, "machines").Placeholder(DollarPlaceholder))
+}
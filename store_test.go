@@ -0,0 +1,73 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_MemoryStore(t *testing.T) {
+	test_Store(t, NewMemoryStore[LampEvent, LampStatus]())
+}
+
+func test_Store(t *testing.T, store Store[LampEvent, LampStatus]) {
+	ctx := context.Background()
+
+	if _, _, err := store.Load(ctx, "lamp-1"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("expected 'ErrSnapshotNotFound', but got %v", err)
+	}
+	snapshot := Snapshot[LampEvent, LampStatus]{Version: SnapshotVersion, Name: "lamp", State: LampStatus_Opened}
+	if err := store.Save(ctx, "lamp-1", snapshot, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected 'ErrVersionConflict' with version 1, but got %v", err)
+	}
+	if err := store.Save(ctx, "lamp-1", snapshot, 0); err != nil {
+		t.Fatalf("save failed %v", err)
+	}
+	if err := store.Save(ctx, "lamp-1", snapshot, 0); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected 'ErrVersionConflict' with version 0, but got %v", err)
+	}
+	got, version, err := store.Load(ctx, "lamp-1")
+	if err != nil {
+		t.Fatalf("load failed %v", err)
+	}
+	if version != 1 || got.State != LampStatus_Opened || got.Name != "lamp" {
+		t.Errorf("expected snapshot %+v with version 1, but got %+v with version %d", snapshot, got, version)
+	}
+
+	p := NewPersistentFsm[LampEvent, LampStatus](
+		NewSafeFsm[LampEvent, LampStatus](LampStatus_Closed, newTestSnapshotTransition()),
+		store,
+	)
+	fsm, err := p.Trigger(ctx, "lamp-1", LampEvent_Close)
+	if err != nil {
+		t.Fatalf("trigger failed %v", err)
+	}
+	if !fsm.Is(LampStatus_Closed) {
+		t.Errorf("expected state to be 'closed', but got %s", fsm.Current())
+	}
+	if _, err = p.Trigger(ctx, "lamp-1", LampEvent_Close); !errors.Is(err, ErrInappropriateEvent) {
+		t.Errorf("expected 'ErrInappropriateEvent', but got %v", err)
+	}
+	fsm, err = p.Trigger(ctx, "lamp-2", LampEvent_Open)
+	if err != nil {
+		t.Fatalf("trigger failed %v", err)
+	}
+	if !fsm.Is(LampStatus_Opened) {
+		t.Errorf("expected state to be 'opened', but got %s", fsm.Current())
+	}
+
+	// another one saved the machine after it loaded.
+	fsm, version, err = p.Load(ctx, "lamp-2")
+	if err != nil {
+		t.Fatalf("load failed %v", err)
+	}
+	if _, err = p.Trigger(ctx, "lamp-2", LampEvent_Close); err != nil {
+		t.Fatalf("trigger failed %v", err)
+	}
+	if err = fsm.Trigger(LampEvent_Close); err != nil {
+		t.Fatalf("trigger failed %v", err)
+	}
+	if err = store.Save(ctx, "lamp-2", fsm.Snapshot(), version); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected 'ErrVersionConflict' with stale version, but got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package fsm
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"strings"
+
+	"golang.org/x/exp/constraints"
+)
+
+// TableFormat the format of the transition table
+type TableFormat string
+
+const (
+	// Markdown the format for GitHub flavored markdown table
+	Markdown TableFormat = "markdown"
+	// CSV the format for comma-separated values (RFC 4180)
+	CSV TableFormat = "csv"
+	// HTML the format for html table
+	HTML TableFormat = "html"
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`|`, `\|`,
+	"\r\n", `<br>`,
+	"\r", `<br>`,
+	"\n", `<br>`,
+)
+
+type tableCell struct {
+	text string
+	err  bool
+}
+
+// TransitionTable outputs the transition table of the transition in the desired format,
+// one row per edge with the source state, the event and the destination state in their names,
+// the guard column is added if there is any guarded edge.
+func TransitionTable[E constraints.Ordered, S constraints.Ordered](ts ITransition[E, S], format TableFormat) (string, error) {
+	edges := sortedEdges[E, S](ts)
+	guarded := false
+	for _, edge := range edges {
+		guarded = guarded || edge.Guard != ""
+	}
+	header := []string{"Source", "Event", "Destination"}
+	if guarded {
+		header = append(header, "Guard")
+	}
+	rows := make([][]tableCell, 0, len(edges))
+	for _, edge := range edges {
+		row := []tableCell{
+			{text: ts.StateName(edge.Src)},
+			{text: ts.EventName(edge.Event)},
+			{text: ts.StateName(edge.Dst)},
+		}
+		if guarded {
+			row = append(row, tableCell{text: edge.Guard})
+		}
+		rows = append(rows, row)
+	}
+	return writeTable(format, header, rows)
+}
+
+// TransitionMatrix outputs the state × event matrix of the transition in the desired format,
+// the cell is the destination state of the event in the source state,
+// the empty cell shows the error which Transform would return.
+func TransitionMatrix[E constraints.Ordered, S constraints.Ordered](ts ITransition[E, S], format TableFormat) (string, error) {
+	states := ts.SortedStates()
+	events := ts.SortedEvents()
+	dsts := make(map[TriggerSource[E, S]][]string)
+	for _, edge := range sortedEdges[E, S](ts) {
+		ss := TriggerSource[E, S]{edge.Event, edge.Src}
+		dst := ts.StateName(edge.Dst)
+		if edge.Guard != "" {
+			dst = fmt.Sprintf("%s [%s]", dst, edge.Guard)
+		}
+		dsts[ss] = append(dsts[ss], dst)
+	}
+
+	header := make([]string, 0, len(events)+1)
+	header = append(header, "State")
+	for _, event := range events {
+		header = append(header, ts.EventName(event))
+	}
+	rows := make([][]tableCell, 0, len(states))
+	for _, state := range states {
+		row := make([]tableCell, 0, len(events)+1)
+		row = append(row, tableCell{text: ts.StateName(state)})
+		for _, event := range events {
+			if dst, ok := dsts[TriggerSource[E, S]{event, state}]; ok {
+				row = append(row, tableCell{text: strings.Join(dst, ", ")})
+			} else if _, err := ts.Transform(state, event); err != nil {
+				row = append(row, tableCell{text: err.Error(), err: true})
+			} else {
+				row = append(row, tableCell{})
+			}
+		}
+		rows = append(rows, row)
+	}
+	return writeTable(format, header, rows)
+}
+
+func writeTable(format TableFormat, header []string, rows [][]tableCell) (string, error) {
+	switch format {
+	case Markdown:
+		return writeMarkdownTable(header, rows), nil
+	case CSV:
+		return writeCSVTable(header, rows)
+	case HTML:
+		return writeHTMLTable(header, rows), nil
+	default:
+		return "", fmt.Errorf("unknown TableFormat: %s", format)
+	}
+}
+
+func writeMarkdownTable(header []string, rows [][]tableCell) string {
+	buf := strings.Builder{}
+	writeRow := func(cells []string) {
+		buf.WriteString("|")
+		for _, cell := range cells {
+			if cell == "" {
+				buf.WriteString(" |")
+			} else {
+				buf.WriteString(" " + markdownEscaper.Replace(cell) + " |")
+			}
+		}
+		buf.WriteString("\n")
+	}
+	writeRow(header)
+	buf.WriteString("|")
+	for range header {
+		buf.WriteString(" --- |")
+	}
+	buf.WriteString("\n")
+	for _, row := range rows {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			cells = append(cells, cell.text)
+		}
+		writeRow(cells)
+	}
+	return buf.String()
+}
+
+func writeCSVTable(header []string, rows [][]tableCell) (string, error) {
+	buf := strings.Builder{}
+	w := csv.NewWriter(&buf)
+	records := make([][]string, 0, len(rows)+1)
+	records = append(records, header)
+	for _, row := range rows {
+		record := make([]string, 0, len(row))
+		for _, cell := range row {
+			record = append(record, cell.text)
+		}
+		records = append(records, record)
+	}
+	if err := w.WriteAll(records); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func writeHTMLTable(header []string, rows [][]tableCell) string {
+	escape := func(s string) string {
+		return strings.ReplaceAll(html.EscapeString(s), "\n", "<br>")
+	}
+	buf := strings.Builder{}
+	buf.WriteString("<table>\n")
+	buf.WriteString("  <thead>\n")
+	buf.WriteString("    <tr>")
+	for _, cell := range header {
+		buf.WriteString("<th>" + escape(cell) + "</th>")
+	}
+	buf.WriteString("</tr>\n")
+	buf.WriteString("  </thead>\n")
+	buf.WriteString("  <tbody>\n")
+	for _, row := range rows {
+		buf.WriteString("    <tr>")
+		for _, cell := range row {
+			if cell.err {
+				buf.WriteString(`<td class="error">` + escape(cell.text) + "</td>")
+			} else {
+				buf.WriteString("<td>" + escape(cell.text) + "</td>")
+			}
+		}
+		buf.WriteString("</tr>\n")
+	}
+	buf.WriteString("  </tbody>\n")
+	buf.WriteString("</table>\n")
+	return buf.String()
+}
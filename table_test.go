@@ -0,0 +1,158 @@
+package fsm
+
+import (
+	"testing"
+)
+
+func newTestTableTransition() *Transition[LampEvent, LampStatus] {
+	return NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+		{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+		{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+		{
+			Event:     LampEvent_PartialClose,
+			Src:       []LampStatus{LampStatus_Intermediate},
+			Dst:       LampStatus_Closed,
+			Guard:     func(e *Event[LampEvent, LampStatus]) bool { return true },
+			GuardName: "half|way",
+		},
+	}).
+		StateNames(map[LampStatus]string{
+			LampStatus_Intermediate: "inter, mediate",
+		}).
+		Build()
+}
+
+func Test_TransitionTable(t *testing.T) {
+	tests := []struct {
+		name   string
+		format TableFormat
+		wanted string
+	}{
+		{
+			name:   "markdown",
+			format: Markdown,
+			wanted: `| Source | Event | Destination | Guard |
+| --- | --- | --- | --- |
+| closed | open | opened | |
+| inter, mediate | partial-close | closed | half\|way |
+| opened | close | closed | |
+`,
+		},
+		{
+			name:   "csv",
+			format: CSV,
+			wanted: `Source,Event,Destination,Guard
+closed,open,opened,
+"inter, mediate",partial-close,closed,half|way
+opened,close,closed,
+`,
+		},
+		{
+			name:   "html",
+			format: HTML,
+			wanted: `<table>
+  <thead>
+    <tr><th>Source</th><th>Event</th><th>Destination</th><th>Guard</th></tr>
+  </thead>
+  <tbody>
+    <tr><td>closed</td><td>open</td><td>opened</td><td></td></tr>
+    <tr><td>inter, mediate</td><td>partial-close</td><td>closed</td><td>half|way</td></tr>
+    <tr><td>opened</td><td>close</td><td>closed</td><td></td></tr>
+  </tbody>
+</table>
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TransitionTable[LampEvent, LampStatus](newTestTableTransition(), tt.format)
+			if err != nil {
+				t.Errorf("got error for transition table with format %s: %s", tt.format, err)
+			}
+			if got != tt.wanted {
+				t.Errorf("build transition table failed. \nwanted \n%s\nand got \n%s\n", tt.wanted, got)
+			}
+		})
+	}
+}
+
+func Test_TransitionMatrix(t *testing.T) {
+	tests := []struct {
+		name   string
+		format TableFormat
+		wanted string
+	}{
+		{
+			name:   "markdown",
+			format: Markdown,
+			wanted: `| State | close | open | partial-close |
+| --- | --- | --- | --- |
+| closed | fsm: event inappropriate in the state | opened | fsm: event inappropriate in the state |
+| inter, mediate | fsm: event inappropriate in the state | fsm: event inappropriate in the state | closed [half\|way] |
+| opened | closed | fsm: event inappropriate in the state | fsm: event inappropriate in the state |
+`,
+		},
+		{
+			name:   "csv",
+			format: CSV,
+			wanted: `State,close,open,partial-close
+closed,fsm: event inappropriate in the state,opened,fsm: event inappropriate in the state
+"inter, mediate",fsm: event inappropriate in the state,fsm: event inappropriate in the state,closed [half|way]
+opened,closed,fsm: event inappropriate in the state,fsm: event inappropriate in the state
+`,
+		},
+		{
+			name:   "html",
+			format: HTML,
+			wanted: `<table>
+  <thead>
+    <tr><th>State</th><th>close</th><th>open</th><th>partial-close</th></tr>
+  </thead>
+  <tbody>
+    <tr><td>closed</td><td class="error">fsm: event inappropriate in the state</td><td>opened</td><td class="error">fsm: event inappropriate in the state</td></tr>
+    <tr><td>inter, mediate</td><td class="error">fsm: event inappropriate in the state</td><td class="error">fsm: event inappropriate in the state</td><td>closed [half|way]</td></tr>
+    <tr><td>opened</td><td>closed</td><td class="error">fsm: event inappropriate in the state</td><td class="error">fsm: event inappropriate in the state</td></tr>
+  </tbody>
+</table>
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TransitionMatrix[LampEvent, LampStatus](newTestTableTransition(), tt.format)
+			if err != nil {
+				t.Errorf("got error for transition matrix with format %s: %s", tt.format, err)
+			}
+			if got != tt.wanted {
+				t.Errorf("build transition matrix failed. \nwanted \n%s\nand got \n%s\n", tt.wanted, got)
+			}
+		})
+	}
+}
+
+func Test_TransitionMatrix_TranslatorError(t *testing.T) {
+	ts := NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+		{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+		{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+	}).
+		TranslatorError(testTranslatorError{}).
+		Build()
+	got, err := TransitionMatrix[LampEvent, LampStatus](ts, CSV)
+	if err != nil {
+		t.Errorf("got error for transition matrix: %s", err)
+	}
+	wanted := `State,close,open
+closed,err1,opened
+opened,closed,err1
+`
+	if got != wanted {
+		t.Errorf("build transition matrix failed. \nwanted \n%s\nand got \n%s\n", wanted, got)
+	}
+}
+
+func Test_TransitionTable_UnknownFormat(t *testing.T) {
+	_, err := TransitionTable[LampEvent, LampStatus](newTestTableTransition(), "xlsx")
+	if err == nil {
+		t.Errorf("expected error for unknown table format")
+	}
+}
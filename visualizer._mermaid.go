@@ -5,10 +5,9 @@ import (
 	"strings"
 
 	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
 )
 
-const highlightingColor = "#00AA00"
-
 // MermaidType the type of the mermaid diagram type
 type MermaidType string
 
@@ -20,41 +19,82 @@ const (
 )
 
 // VisualizeMermaid outputs a visualization of a Fsm in Mermaid format as specified by the graphType.
-func VisualizeMermaid[E constraints.Ordered, S constraints.Ordered](t MermaidType, fsm Visualizer[E, S]) (string, error) {
+func VisualizeMermaid[E constraints.Ordered, S constraints.Ordered](t MermaidType, fsm Visualizer[E, S], opts ...VisualizeOption) (string, error) {
+	o, err := newVisualizeOptions[S](opts...)
+	if err != nil {
+		return "", err
+	}
 	switch t {
 	case FlowChart:
-		return visualizeMermaidFlowChart(fsm)
+		return visualizeMermaidFlowChart(fsm, o)
 	case StateDiagram:
-		return visualizeMermaidStateDiagram(fsm)
+		return visualizeMermaidStateDiagram(fsm, o)
 	default:
 		return "", fmt.Errorf("unknown MermaidDiagramType: %s", t)
 	}
 }
 
-func visualizeMermaidStateDiagram[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S]) (string, error) {
-	sortedTriggerSources := fsm.SortedTriggerSource()
+func visualizeMermaidStateDiagram[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], opts *visualizeOptions) (string, error) {
+	sortedEdges := sortedEdges[E, S](fsm)
+	sortedStates := intoSortedStates(opts, fsm)
+	statesKind := intoStateKinds(opts, sortedStates, sortedEdges)
+	// the states are declared with the ids, so the states with the same name are not merged.
+	statesId := intoStateIds(sortedStates)
 	buf := strings.Builder{}
 	if fsm.Name() != "" {
 		buf.WriteString("---\n")
-		buf.WriteString(fmt.Sprintf("title: %s\n", fsm.Name()))
+		buf.WriteString(fmt.Sprintf("title: %s\n", yamlText(fsm.Name())))
 		buf.WriteString("---\n")
 	}
 	buf.WriteString("stateDiagram-v2\n")
-	buf.WriteString(fmt.Sprintln(`    [*] -->`, fsm.StateName(fsm.Current())))
-	for _, ts := range sortedTriggerSources {
-		dst, err := fsm.Transform(ts.State(), ts.Event())
-		if err != nil {
-			return "", err
+	if opts.direction != "" {
+		buf.WriteString(fmt.Sprintln(`    direction`, opts.direction))
+	}
+	for _, state := range sortedStates {
+		buf.WriteString(fmt.Sprintf(`    state "%s" as %s`, mermaidLabel(fsm.StateName(state)), statesId[state]))
+		buf.WriteString("\n")
+	}
+	// the state diagram has no shape, the initial and terminal states are marked with the start and end point.
+	for _, state := range sortedStates {
+		if statesKind[state] == stateKindInitial && !(opts.currentMarker && state == fsm.Current()) {
+			buf.WriteString(fmt.Sprintln(`    [*] -->`, statesId[state]))
 		}
-		buf.WriteString(fmt.Sprintf(`    %s --> %s: %s`, fsm.StateName(ts.State()), fsm.StateName(dst), fsm.EventName(ts.Event())))
+	}
+	if opts.currentMarker {
+		buf.WriteString(fmt.Sprintln(`    [*] -->`, statesId[fsm.Current()]))
+	}
+	for _, edge := range sortedEdges {
+		buf.WriteString(fmt.Sprintf(`    %s --> %s: %s`, statesId[edge.Src], statesId[edge.Dst], mermaidLabel(edgeLabel(fsm, edge))))
 		buf.WriteString("\n")
 	}
+	for _, state := range sortedStates {
+		if statesKind[state] == stateKindTerminal {
+			buf.WriteString(fmt.Sprintf(`    %s --> [*]`, statesId[state]))
+			buf.WriteString("\n")
+		}
+	}
+	// the state diagram can not style the transitions, the current state is filled with the highlight color,
+	// and the destination states of the available events are outlined with it.
+	if opts.currentMarker {
+		buf.WriteString(fmt.Sprintf("    classDef current fill:%s\n", opts.highlightColor))
+		buf.WriteString(fmt.Sprintf("    class %s current\n", statesId[fsm.Current()]))
+	}
+	availStates := make([]string, 0)
+	for _, edge := range sortedEdges {
+		if isAvailEdge(opts, fsm, edge) && !(opts.currentMarker && edge.Dst == fsm.Current()) && !slices.Contains(availStates, statesId[edge.Dst]) {
+			availStates = append(availStates, statesId[edge.Dst])
+		}
+	}
+	if len(availStates) > 0 {
+		buf.WriteString(fmt.Sprintf("    classDef avail stroke:%s\n", opts.highlightColor))
+		buf.WriteString(fmt.Sprintf("    class %s avail\n", strings.Join(availStates, ",")))
+	}
 	return buf.String(), nil
 }
 
 // visualizeMermaidFlowChart outputs a visualization of a Fsm in Mermaid format (including highlighting of current state).
-func visualizeMermaidFlowChart[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S]) (string, error) {
-	v := newVisualizeMermaidFlowChartBuilder(fsm).
+func visualizeMermaidFlowChart[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], opts *visualizeOptions) (string, error) {
+	v := newVisualizeMermaidFlowChartBuilder(fsm, opts).
 		writeFlowChartGraphType().
 		writeFlowChartStates().
 		writeFlowChartTransitions().
@@ -66,23 +106,27 @@ func visualizeMermaidFlowChart[E constraints.Ordered, S constraints.Ordered](fsm
 }
 
 type visualizeMermaidFlowChartBuilder[E constraints.Ordered, S constraints.Ordered] struct {
-	fsm                  Visualizer[E, S]
-	sortedTriggerSources []TriggerSource[E, S] // we sort the key alphabetically to have a reproducible graph output
-	sortedStates         []S
-	statesId             map[S]string
-	buf                  strings.Builder
-	err                  error
+	fsm          Visualizer[E, S]
+	opts         *visualizeOptions
+	sortedEdges  []Edge[E, S] // we sort the key alphabetically to have a reproducible graph output
+	sortedStates []S
+	statesId     map[S]string
+	statesKind   map[S]stateKind
+	buf          strings.Builder
+	err          error
 }
 
-func newVisualizeMermaidFlowChartBuilder[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S]) *visualizeMermaidFlowChartBuilder[E, S] {
-	sortedTriggerSources := fsm.SortedTriggerSource()
-	sortedStates := fsm.SortedStates()
-	statesId := intoSortedStateId(sortedStates)
+func newVisualizeMermaidFlowChartBuilder[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], opts *visualizeOptions) *visualizeMermaidFlowChartBuilder[E, S] {
+	sortedEdges := sortedEdges[E, S](fsm)
+	sortedStates := intoSortedStates(opts, fsm)
+	statesId := intoStateIds(sortedStates)
 	return &visualizeMermaidFlowChartBuilder[E, S]{
-		fsm:                  fsm,
-		sortedTriggerSources: sortedTriggerSources,
-		sortedStates:         sortedStates,
-		statesId:             statesId,
+		fsm:          fsm,
+		opts:         opts,
+		sortedEdges:  sortedEdges,
+		sortedStates: sortedStates,
+		statesId:     statesId,
+		statesKind:   intoStateKinds(opts, sortedStates, sortedEdges),
 	}
 }
 
@@ -92,10 +136,14 @@ func (v *visualizeMermaidFlowChartBuilder[E, S]) writeFlowChartGraphType() *visu
 	}
 	if v.fsm.Name() != "" {
 		v.buf.WriteString("---\n")
-		v.buf.WriteString(fmt.Sprintf("title: %s\n", v.fsm.Name()))
+		v.buf.WriteString(fmt.Sprintf("title: %s\n", yamlText(v.fsm.Name())))
 		v.buf.WriteString("---\n")
 	}
-	v.buf.WriteString("graph LR\n")
+	direction := v.opts.direction
+	if direction == "" {
+		direction = LeftToRight
+	}
+	v.buf.WriteString(fmt.Sprintf("graph %s\n", direction))
 	return v
 }
 
@@ -104,7 +152,18 @@ func (v *visualizeMermaidFlowChartBuilder[E, S]) writeFlowChartStates() *visuali
 		return v
 	}
 	for _, state := range v.sortedStates {
-		v.buf.WriteString(fmt.Sprintf(`    %s[%s]`, v.statesId[state], v.fsm.StateName(state)))
+		var format string
+		switch v.opts.shape(v.statesKind[state]) {
+		case ShapeRounded:
+			format = `    %s(%s)`
+		case ShapeCircle:
+			format = `    %s((%s))`
+		case ShapeDoubleCircle:
+			format = `    %s(((%s)))`
+		default:
+			format = `    %s[%s]`
+		}
+		v.buf.WriteString(fmt.Sprintf(format, v.statesId[state], mermaidText(v.fsm.StateName(state))))
 		v.buf.WriteString("\n")
 	}
 	v.buf.WriteString("\n")
@@ -115,12 +174,8 @@ func (v *visualizeMermaidFlowChartBuilder[E, S]) writeFlowChartTransitions() *vi
 	if v.err != nil {
 		return v
 	}
-	for _, ts := range v.sortedTriggerSources {
-		dst, err := v.fsm.Transform(ts.State(), ts.Event())
-		if err != nil {
-			return v.setErr(err)
-		}
-		v.buf.WriteString(fmt.Sprintf(`    %s --> |%s| %s`, v.statesId[ts.State()], v.fsm.EventName(ts.Event()), v.statesId[dst]))
+	for _, edge := range v.sortedEdges {
+		v.buf.WriteString(fmt.Sprintf(`    %s --> |%s| %s`, v.statesId[edge.Src], mermaidText(edgeLabel(v.fsm, edge)), v.statesId[edge.Dst]))
 		v.buf.WriteString("\n")
 	}
 	v.buf.WriteString("\n")
@@ -131,10 +186,19 @@ func (v *visualizeMermaidFlowChartBuilder[E, S]) writeFlowChartHighlightCurrent(
 	if v.err != nil {
 		return v
 	}
-	v.buf.WriteString(fmt.Sprintf(`    style %s fill:%s`, v.statesId[v.fsm.Current()], highlightingColor))
-	v.buf.WriteString("\n")
+	for i, edge := range v.sortedEdges {
+		if isAvailEdge(v.opts, v.fsm, edge) {
+			v.buf.WriteString(fmt.Sprintf(`    linkStyle %d stroke:%s`, i, v.opts.highlightColor))
+			v.buf.WriteString("\n")
+		}
+	}
+	if v.opts.currentMarker {
+		v.buf.WriteString(fmt.Sprintf(`    style %s fill:%s`, v.statesId[v.fsm.Current()], v.opts.highlightColor))
+		v.buf.WriteString("\n")
+	}
 	return v
 }
+
 func (v *visualizeMermaidFlowChartBuilder[E, S]) setErr(err error) *visualizeMermaidFlowChartBuilder[E, S] {
 	v.err = err
 	return v
@@ -148,10 +212,21 @@ func (v *visualizeMermaidFlowChartBuilder[E, S]) String() string {
 	return v.buf.String()
 }
 
-func intoSortedStateId[S constraints.Ordered](sortedStates []S) map[S]string {
-	statesId := make(map[S]string)
-	for i, state := range sortedStates {
-		statesId[state] = fmt.Sprintf("id%d", i)
+// intoStateIds returns the ids of the states, which are derived from the state values with the prefix "s_",
+// so the ids of the other states are kept when a state is added or removed.
+// The characters other than the ASCII letters, digits and '_' are replaced by '_',
+// the ids which collide get the suffix of a number in the order of the states.
+func intoStateIds[S constraints.Ordered](sortedStates []S) map[S]string {
+	statesId := make(map[S]string, len(sortedStates))
+	used := make(map[string]bool, len(sortedStates))
+	for _, state := range sortedStates {
+		base := "s_" + stateIdInvalidChars.ReplaceAllString(fmt.Sprint(state), "_")
+		id := base
+		for i := 2; used[id]; i++ {
+			id = fmt.Sprintf("%s_%d", base, i)
+		}
+		used[id] = true
+		statesId[state] = id
 	}
 	return statesId
 }
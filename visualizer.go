@@ -1,6 +1,8 @@
 package fsm
 
 import (
+	"fmt"
+
 	"golang.org/x/exp/constraints"
 )
 
@@ -28,19 +30,45 @@ const (
 	MermaidStateDiagram VisualizeType = "mermaid-state-diagram"
 	// MermaidFlowChart the type for mermaid output (https://mermaid-js.github.io/mermaid/#/flowchart) in the flow chart form
 	MermaidFlowChart VisualizeType = "mermaid-flow-chart"
+	// PlantUML the type for PlantUML output (https://plantuml.com/state-diagram) in the state diagram form
+	PlantUML VisualizeType = "plantuml"
+	// D2 the type for D2 output (https://d2lang.com)
+	D2 VisualizeType = "d2"
+	// SVG the type for SVG output, which is laid out by pure Go without the external Graphviz
+	SVG VisualizeType = "svg"
 )
 
 // Visualize outputs a visualization of a Fsm in the desired format.
 // If the type is not given it defaults to Graphviz
 func Visualize[E constraints.Ordered, S constraints.Ordered](t VisualizeType, fsm Visualizer[E, S]) (string, error) {
+	return VisualizeWithOptions(t, fsm)
+}
+
+// VisualizeWithOptions outputs a visualization of a Fsm in the desired format with the options.
+// If the type is not given it defaults to Graphviz
+func VisualizeWithOptions[E constraints.Ordered, S constraints.Ordered](t VisualizeType, fsm Visualizer[E, S], opts ...VisualizeOption) (string, error) {
 	switch t {
 	case Mermaid, MermaidStateDiagram:
-		return VisualizeMermaid(StateDiagram, fsm)
+		return VisualizeMermaid(StateDiagram, fsm, opts...)
 	case MermaidFlowChart:
-		return VisualizeMermaid(FlowChart, fsm)
+		return VisualizeMermaid(FlowChart, fsm, opts...)
+	case PlantUML:
+		return VisualizePlantUML(fsm, opts...)
+	case D2:
+		return VisualizeD2(fsm, opts...)
+	case SVG:
+		return VisualizeSVG(fsm, opts...)
 	case Graphviz:
 		fallthrough
 	default:
-		return VisualizeGraphviz(fsm)
+		return VisualizeGraphviz(fsm, opts...)
+	}
+}
+
+// edgeLabel returns the label of the edge, the guarded edge is labeled with the guard name.
+func edgeLabel[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], edge Edge[E, S]) string {
+	if edge.Guard == "" {
+		return fsm.EventName(edge.Event)
 	}
+	return fmt.Sprintf("%s [%s]", fsm.EventName(edge.Event), edge.Guard)
 }
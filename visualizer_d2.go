@@ -0,0 +1,114 @@
+package fsm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/constraints"
+)
+
+// VisualizeD2 outputs a visualization of a Fsm in D2 format (including highlighting of current state).
+func VisualizeD2[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], opts ...VisualizeOption) (string, error) {
+	o, err := newVisualizeOptions[S](opts...)
+	if err != nil {
+		return "", err
+	}
+	v := newVisualizeD2Builder(fsm, o).
+		writeHeader().
+		writeStates().
+		writeTransitions()
+	if v.Err() != nil {
+		return "", v.Err()
+	}
+	return v.String(), nil
+}
+
+type visualizeD2Builder[E constraints.Ordered, S constraints.Ordered] struct {
+	fsm          Visualizer[E, S]
+	opts         *visualizeOptions
+	sortedEdges  []Edge[E, S] // we sort the key alphabetically to have a reproducible graph output
+	sortedStates []S
+	statesId     map[S]string
+	buf          strings.Builder
+	err          error
+}
+
+func newVisualizeD2Builder[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], opts *visualizeOptions) *visualizeD2Builder[E, S] {
+	sortedStates := intoSortedStates(opts, fsm)
+	return &visualizeD2Builder[E, S]{
+		fsm:          fsm,
+		opts:         opts,
+		sortedEdges:  sortedEdges[E, S](fsm),
+		sortedStates: sortedStates,
+		statesId:     intoStateIds(sortedStates),
+	}
+}
+
+func (v *visualizeD2Builder[E, S]) writeHeader() *visualizeD2Builder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	if v.opts.direction == TopToBottom {
+		v.buf.WriteString("direction: down\n")
+	} else {
+		v.buf.WriteString("direction: right\n")
+	}
+	if v.fsm.Name() != "" {
+		v.buf.WriteString(fmt.Sprintf("title: \"%s\" {\n", d2Escape(v.fsm.Name())))
+		v.buf.WriteString("  shape: text\n")
+		v.buf.WriteString("  near: top-center\n")
+		v.buf.WriteString("}\n")
+	}
+	return v
+}
+
+func (v *visualizeD2Builder[E, S]) writeStates() *visualizeD2Builder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	for _, state := range v.sortedStates {
+		v.buf.WriteString(fmt.Sprintf(`%s: "%s"`, v.statesId[state], d2Escape(v.fsm.StateName(state))))
+		if v.opts.currentMarker && state == v.fsm.Current() {
+			v.buf.WriteString(" {\n")
+			v.buf.WriteString(fmt.Sprintf("  style.fill: \"%s\"\n", d2Escape(v.opts.highlightColor)))
+			v.buf.WriteString("}")
+		}
+		v.buf.WriteString("\n")
+	}
+	v.buf.WriteString("\n")
+	return v
+}
+
+func (v *visualizeD2Builder[E, S]) writeTransitions() *visualizeD2Builder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	b := bytes.Buffer{}
+	// make sure the current state is at top
+	for _, edge := range v.sortedEdges {
+		line := fmt.Sprintf(`%s -> %s: "%s"`, v.statesId[edge.Src], v.statesId[edge.Dst], d2Escape(edgeLabel(v.fsm, edge)))
+		if isAvailEdge(v.opts, v.fsm, edge) {
+			line += fmt.Sprintf(" {\n  style.stroke: \"%s\"\n}", d2Escape(v.opts.highlightColor))
+		}
+		if edge.Src == v.fsm.Current() {
+			v.buf.WriteString(line)
+			v.buf.WriteString("\n")
+		} else {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	if b.Len() > 0 {
+		v.buf.Write(b.Bytes())
+	}
+	return v
+}
+
+func (v *visualizeD2Builder[E, S]) Err() error {
+	return v.err
+}
+
+func (v *visualizeD2Builder[E, S]) String() string {
+	return v.buf.String()
+}
@@ -0,0 +1,40 @@
+package fsm
+
+import (
+	"testing"
+)
+
+func Test_D2(t *testing.T) {
+	fsmUnderTest := NewFsm[LampEvent, LampStatus](
+		LampStatus_Opened,
+		NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+			{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+			{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+			{Event: LampEvent_PartialClose, Src: []LampStatus{LampStatus_Intermediate}, Dst: LampStatus_Closed},
+		}).
+			Name("Lamp FSM").
+			Build(),
+	)
+	got, err := fsmUnderTest.Visualize(D2)
+	if err != nil {
+		t.Errorf("got error for visualizing with type D2: %s", err)
+	}
+	wanted := `direction: right
+title: "Lamp FSM" {
+  shape: text
+  near: top-center
+}
+s_closed: "closed"
+s_intermediate: "intermediate"
+s_opened: "opened" {
+  style.fill: "#00AA00"
+}
+
+s_opened -> s_closed: "close"
+s_closed -> s_opened: "open"
+s_intermediate -> s_closed: "partial-close"
+`
+	if got != wanted {
+		t.Errorf("build d2 graph failed. \nwanted \n%s\nand got \n%s\n", wanted, got)
+	}
+}
@@ -0,0 +1,76 @@
+package fsm
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// graphvizEscaper escapes the text in the double-quoted string of Graphviz.
+	graphvizEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\r\n", `\n`,
+		"\r", `\n`,
+		"\n", `\n`,
+	)
+	// mermaidEscaper escapes the text of Mermaid with the entity codes.
+	mermaidEscaper = strings.NewReplacer(
+		`#`, `#35;`,
+		`"`, `#quot;`,
+		`;`, `#59;`,
+		`|`, `#124;`,
+		`<`, `#lt;`,
+		`>`, `#gt;`,
+		"\r\n", `<br>`,
+		"\r", `<br>`,
+		"\n", `<br>`,
+	)
+	// plantUMLEscaper escapes the text of PlantUML.
+	plantUMLEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `<U+0022>`,
+		"\r\n", `\n`,
+		"\r", `\n`,
+		"\n", `\n`,
+	)
+	// d2Escaper escapes the text in the double-quoted string of D2, the substitution is escaped too.
+	d2Escaper = strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		`$`, `\$`,
+		"\r\n", `\n`,
+		"\r", `\n`,
+		"\n", `\n`,
+	)
+
+	mermaidPlainText    = regexp.MustCompile(`^[\p{L}\p{N}_. -]*$`)
+	yamlPlainText       = regexp.MustCompile(`^[\p{L}\p{N}_.() -]*$`)
+	stateIdInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+)
+
+func graphvizEscape(s string) string { return graphvizEscaper.Replace(s) }
+func plantUMLEscape(s string) string { return plantUMLEscaper.Replace(s) }
+func d2Escape(s string) string       { return d2Escaper.Replace(s) }
+
+// mermaidText returns the text of Mermaid, the text which is not plain is escaped and quoted.
+func mermaidText(s string) string {
+	if mermaidPlainText.MatchString(s) {
+		return s
+	}
+	return `"` + mermaidEscaper.Replace(s) + `"`
+}
+
+// mermaidLabel returns the escaped label of the Mermaid state diagram transition, which ends with the line.
+func mermaidLabel(s string) string {
+	return mermaidEscaper.Replace(s)
+}
+
+// yamlText returns the text of the YAML front matter, the text which is not plain is quoted.
+func yamlText(s string) string {
+	if yamlPlainText.MatchString(s) && strings.TrimSpace(s) == s {
+		return s
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,194 @@
+package fsm
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// newTestHostileFsm returns the Fsm with the names which break the diagrams without escaping.
+func newTestHostileFsm() IFsm[string, string] {
+	return NewFsm[string, string](
+		`say "hi"`,
+		NewTransitionBuilder([]Transform[string, string]{
+			{Event: `go "now"`, Src: []string{`say "hi"`}, Dst: "a|b: [c]"},
+			{Event: "p|q;#1", Src: []string{"a|b: [c]"}, Dst: "line1\nline2"},
+			{Event: "<tag> $x", Src: []string{"line1\nline2"}, Dst: `back\slash`},
+		}).
+			Name(`My "FSM": v1`).
+			Build(),
+	)
+}
+
+func Test_Visualize_Escape(t *testing.T) {
+	tests := []struct {
+		name   string
+		t      VisualizeType
+		wanted string
+	}{
+		{
+			name: "graphviz",
+			t:    Graphviz,
+			wanted: `digraph fsm {
+    label="My \"FSM\": v1"
+    s_say_hi_ -> s_a_b_c_ [ label = "go \"now\"" ];
+    s_a_b_c_ -> s_line1_line2 [ label = "p|q;#1" ];
+    s_line1_line2 -> s_back_slash [ label = "<tag> $x" ];
+
+    s_a_b_c_ [ label = "a|b: [c]" ];
+    s_back_slash [ label = "back\\slash" ];
+    s_line1_line2 [ label = "line1\nline2" ];
+    s_say_hi_ [ label = "say \"hi\"", style = "filled", fillcolor = "#00AA00" ];
+}
+`,
+		},
+		{
+			name: "mermaid-state-diagram",
+			t:    MermaidStateDiagram,
+			wanted: `---
+title: "My \"FSM\": v1"
+---
+stateDiagram-v2
+    state "a#124;b: [c]" as s_a_b_c_
+    state "back\slash" as s_back_slash
+    state "line1<br>line2" as s_line1_line2
+    state "say #quot;hi#quot;" as s_say_hi_
+    [*] --> s_say_hi_
+    s_a_b_c_ --> s_line1_line2: p#124;q#59;#35;1
+    s_line1_line2 --> s_back_slash: #lt;tag#gt; $x
+    s_say_hi_ --> s_a_b_c_: go #quot;now#quot;
+    classDef current fill:#00AA00
+    class s_say_hi_ current
+`,
+		},
+		{
+			name: "mermaid-flow-chart",
+			t:    MermaidFlowChart,
+			wanted: `---
+title: "My \"FSM\": v1"
+---
+graph LR
+    s_a_b_c_["a#124;b: [c]"]
+    s_back_slash["back\slash"]
+    s_line1_line2["line1<br>line2"]
+    s_say_hi_["say #quot;hi#quot;"]
+
+    s_a_b_c_ --> |"p#124;q#59;#35;1"| s_line1_line2
+    s_line1_line2 --> |"#lt;tag#gt; $x"| s_back_slash
+    s_say_hi_ --> |"go #quot;now#quot;"| s_a_b_c_
+
+    style s_say_hi_ fill:#00AA00
+`,
+		},
+		{
+			name: "plantuml",
+			t:    PlantUML,
+			wanted: `@startuml
+title My <U+0022>FSM<U+0022>: v1
+state "a|b: [c]" as s_a_b_c_
+state "back\\slash" as s_back_slash
+state "line1\nline2" as s_line1_line2
+state "say <U+0022>hi<U+0022>" as s_say_hi_ #00AA00
+
+[*] --> s_say_hi_
+s_a_b_c_ --> s_line1_line2 : p|q;#1
+s_line1_line2 --> s_back_slash : <tag> $x
+s_say_hi_ --> s_a_b_c_ : go <U+0022>now<U+0022>
+@enduml
+`,
+		},
+		{
+			name: "d2",
+			t:    D2,
+			wanted: `direction: right
+title: "My \"FSM\": v1" {
+  shape: text
+  near: top-center
+}
+s_a_b_c_: "a|b: [c]"
+s_back_slash: "back\\slash"
+s_line1_line2: "line1\nline2"
+s_say_hi_: "say \"hi\"" {
+  style.fill: "#00AA00"
+}
+
+s_say_hi_ -> s_a_b_c_: "go \"now\""
+s_a_b_c_ -> s_line1_line2: "p|q;#1"
+s_line1_line2 -> s_back_slash: "<tag> \$x"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestHostileFsm().Visualize(tt.t)
+			if err != nil {
+				t.Errorf("got error for visualizing with type %s: %s", tt.t, err)
+			}
+			if got != tt.wanted {
+				t.Errorf("build graph failed. \nwanted \n%s\nand got \n%s\n", tt.wanted, got)
+			}
+		})
+	}
+}
+
+func Test_Visualize_Escape_SVG(t *testing.T) {
+	got, err := newTestHostileFsm().Visualize(SVG)
+	if err != nil {
+		t.Errorf("got error for visualizing with type SVG: %s", err)
+	}
+	var texts []string
+	decoder := xml.NewDecoder(strings.NewReader(got))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("svg is not well-formed: %s", err)
+			}
+			break
+		}
+		if data, ok := token.(xml.CharData); ok && strings.TrimSpace(string(data)) != "" {
+			texts = append(texts, string(data))
+		}
+	}
+	for _, want := range []string{`My "FSM": v1`, `say "hi"`, "a|b: [c]", `back\slash`, `go "now"`, "<tag> $x"} {
+		found := false
+		for _, text := range texts {
+			found = found || text == want
+		}
+		if !found {
+			t.Errorf("svg text %q not found in %q", want, texts)
+		}
+	}
+}
+
+func Test_Visualize_Escape_MermaidStateDiagram_Collision(t *testing.T) {
+	// the states with the same name, or with a name equal to an id, are not merged.
+	fsm := NewFsm[string, string](
+		"a",
+		NewTransitionBuilder([]Transform[string, string]{
+			{Event: "go", Src: []string{"a"}, Dst: "b"},
+			{Event: "next", Src: []string{"b"}, Dst: "c"},
+		}).
+			StateNames(map[string]string{"a": "Same", "b": "Same", "c": "id0"}).
+			Build(),
+	)
+	got, err := fsm.Visualize(MermaidStateDiagram)
+	if err != nil {
+		t.Errorf("got error for visualizing with type %s: %s", MermaidStateDiagram, err)
+	}
+	wanted := `stateDiagram-v2
+    state "Same" as s_a
+    state "Same" as s_b
+    state "id0" as s_c
+    [*] --> s_a
+    s_a --> s_b: go
+    s_b --> s_c: next
+    classDef current fill:#00AA00
+    class s_a current
+`
+	if got != wanted {
+		t.Errorf("build graph failed. \nwanted \n%s\nand got \n%s\n", wanted, got)
+	}
+}
@@ -8,9 +8,13 @@ import (
 	"golang.org/x/exp/constraints"
 )
 
-// VisualizeGraphviz outputs a visualization of a Fsm in Graphviz format.
-func VisualizeGraphviz[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S]) (string, error) {
-	v := newVisualizeGraphvizBuilder(fsm).
+// VisualizeGraphviz outputs a visualization of a Fsm in Graphviz format (including highlighting of current state).
+func VisualizeGraphviz[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], opts ...VisualizeOption) (string, error) {
+	o, err := newVisualizeOptions[S](opts...)
+	if err != nil {
+		return "", err
+	}
+	v := newVisualizeGraphvizBuilder(fsm, o).
 		writeHeaderLine().
 		writeTransitions().
 		writeStates().
@@ -22,18 +26,26 @@ func VisualizeGraphviz[E constraints.Ordered, S constraints.Ordered](fsm Visuali
 }
 
 type visualizeGraphvizBuilder[E constraints.Ordered, S constraints.Ordered] struct {
-	fsm                  Visualizer[E, S]
-	sortedTriggerSources []TriggerSource[E, S] // we sort the key alphabetically to have a reproducible graph output
-	sortedStates         []S
-	buf                  strings.Builder
-	err                  error
+	fsm          Visualizer[E, S]
+	opts         *visualizeOptions
+	sortedEdges  []Edge[E, S] // we sort the key alphabetically to have a reproducible graph output
+	sortedStates []S
+	statesId     map[S]string
+	statesKind   map[S]stateKind
+	buf          strings.Builder
+	err          error
 }
 
-func newVisualizeGraphvizBuilder[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S]) *visualizeGraphvizBuilder[E, S] {
+func newVisualizeGraphvizBuilder[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], opts *visualizeOptions) *visualizeGraphvizBuilder[E, S] {
+	sortedEdges := sortedEdges[E, S](fsm)
+	sortedStates := intoSortedStates(opts, fsm)
 	return &visualizeGraphvizBuilder[E, S]{
-		fsm:                  fsm,
-		sortedTriggerSources: fsm.SortedTriggerSource(),
-		sortedStates:         fsm.SortedStates(),
+		fsm:          fsm,
+		opts:         opts,
+		sortedEdges:  sortedEdges,
+		sortedStates: sortedStates,
+		statesId:     intoStateIds(sortedStates),
+		statesKind:   intoStateKinds(opts, sortedStates, sortedEdges),
 	}
 }
 
@@ -44,7 +56,11 @@ func (v *visualizeGraphvizBuilder[E, S]) writeHeaderLine() *visualizeGraphvizBui
 	v.buf.WriteString("digraph fsm {")
 	v.buf.WriteString("\n")
 	if v.fsm.Name() != "" {
-		v.buf.WriteString(fmt.Sprintf(`    label="%s"`, v.fsm.Name()))
+		v.buf.WriteString(fmt.Sprintf(`    label="%s"`, graphvizEscape(v.fsm.Name())))
+		v.buf.WriteString("\n")
+	}
+	if v.opts.direction != "" {
+		v.buf.WriteString(fmt.Sprintf(`    rankdir=%s`, v.opts.direction))
 		v.buf.WriteString("\n")
 	}
 	return v
@@ -56,13 +72,14 @@ func (v *visualizeGraphvizBuilder[E, S]) writeTransitions() *visualizeGraphvizBu
 	}
 	b := bytes.Buffer{}
 	// make sure the current state is at top
-	for _, ts := range v.sortedTriggerSources {
-		dst, err := v.fsm.Transform(ts.State(), ts.Event())
-		if err != nil {
-			return v.setErr(err)
+	for _, edge := range v.sortedEdges {
+		attrs := fmt.Sprintf(`label = "%s"`, graphvizEscape(edgeLabel(v.fsm, edge)))
+		if isAvailEdge(v.opts, v.fsm, edge) {
+			color := graphvizEscape(v.opts.highlightColor)
+			attrs += fmt.Sprintf(`, color = "%s", fontcolor = "%s"`, color, color)
 		}
-		line := fmt.Sprintf(`    "%s" -> "%s" [ label = "%s" ];`, v.fsm.StateName(ts.State()), v.fsm.StateName(dst), v.fsm.EventName(ts.Event()))
-		if ts.State() == v.fsm.Current() {
+		line := fmt.Sprintf(`    %s -> %s [ %s ];`, v.statesId[edge.Src], v.statesId[edge.Dst], attrs)
+		if edge.Src == v.fsm.Current() {
 			v.buf.WriteString(line)
 			v.buf.WriteString("\n")
 		} else {
@@ -82,7 +99,30 @@ func (v *visualizeGraphvizBuilder[E, S]) writeStates() *visualizeGraphvizBuilder
 		return v
 	}
 	for _, state := range v.sortedStates {
-		v.buf.WriteString(fmt.Sprintf(`    "%s";`, v.fsm.StateName(state)))
+		attrs := []string{fmt.Sprintf(`label = "%s"`, graphvizEscape(v.fsm.StateName(state)))}
+		styles := make([]string, 0, 2)
+		switch v.opts.shape(v.statesKind[state]) {
+		case ShapeRect:
+			attrs = append(attrs, "shape = box")
+		case ShapeRounded:
+			attrs = append(attrs, "shape = box")
+			styles = append(styles, "rounded")
+		case ShapeCircle:
+			attrs = append(attrs, "shape = circle")
+		case ShapeDoubleCircle:
+			attrs = append(attrs, "shape = doublecircle")
+		}
+		highlighted := v.opts.currentMarker && state == v.fsm.Current()
+		if highlighted {
+			styles = append(styles, "filled")
+		}
+		if len(styles) > 0 {
+			attrs = append(attrs, fmt.Sprintf(`style = "%s"`, strings.Join(styles, ",")))
+		}
+		if highlighted {
+			attrs = append(attrs, fmt.Sprintf(`fillcolor = "%s"`, graphvizEscape(v.opts.highlightColor)))
+		}
+		v.buf.WriteString(fmt.Sprintf(`    %s [ %s ];`, v.statesId[state], strings.Join(attrs, ", ")))
 		v.buf.WriteString("\n")
 	}
 	return v
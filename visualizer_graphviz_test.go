@@ -21,13 +21,13 @@ func Test_Graphviz(t *testing.T) {
 	}
 	wanted := `
 digraph fsm {
-    "closed" -> "opened" [ label = "open" ];
-    "intermediate" -> "closed" [ label = "partial-close" ];
-    "opened" -> "closed" [ label = "close" ];
+    s_closed -> s_opened [ label = "open" ];
+    s_intermediate -> s_closed [ label = "partial-close" ];
+    s_opened -> s_closed [ label = "close" ];
 
-    "closed";
-    "intermediate";
-    "opened";
+    s_closed [ label = "closed", style = "filled", fillcolor = "#00AA00" ];
+    s_intermediate [ label = "intermediate" ];
+    s_opened [ label = "opened" ];
 }`
 	normalizedGot := strings.ReplaceAll(got, "\n", "")
 	normalizedWanted := strings.ReplaceAll(wanted, "\n", "")
@@ -62,13 +62,13 @@ func Test_Graphviz_CustomName(t *testing.T) {
 	wanted := `
 digraph fsm {
     label="Lamp FSM"
-    ">closed" -> ">opened" [ label = "<open>" ];
-    ">intermediate" -> ">closed" [ label = "<partial-close>" ];
-    ">opened" -> ">closed" [ label = "<close>" ];
+    s_closed -> s_opened [ label = "<open>" ];
+    s_intermediate -> s_closed [ label = "<partial-close>" ];
+    s_opened -> s_closed [ label = "<close>" ];
 
-    ">closed";
-    ">intermediate";
-    ">opened";
+    s_closed [ label = ">closed", style = "filled", fillcolor = "#00AA00" ];
+    s_intermediate [ label = ">intermediate" ];
+    s_opened [ label = ">opened" ];
 }`
 	normalizedGot := strings.ReplaceAll(got, "\n", "")
 	normalizedWanted := strings.ReplaceAll(wanted, "\n", "")
@@ -78,3 +78,31 @@ digraph fsm {
 		fmt.Println(normalizedWanted)
 	}
 }
+
+func Test_Graphviz_Guard(t *testing.T) {
+	fsmUnderTest := NewFsm[string, string](
+		"pending",
+		NewTransition([]Transform[string, string]{
+			{Event: "approve", Src: []string{"pending"}, Dst: "approved", Guard: func(e *Event[string, string]) bool { return true }, GuardName: "small"},
+			{Event: "approve", Src: []string{"pending"}, Dst: "escalated"},
+		}),
+	)
+	got, err := fsmUnderTest.Visualize(Graphviz)
+	if err != nil {
+		panic(err)
+	}
+	wanted := `
+digraph fsm {
+    s_pending -> s_approved [ label = "approve [small]" ];
+    s_pending -> s_escalated [ label = "approve" ];
+
+    s_approved [ label = "approved" ];
+    s_escalated [ label = "escalated" ];
+    s_pending [ label = "pending", style = "filled", fillcolor = "#00AA00" ];
+}`
+	normalizedGot := strings.ReplaceAll(got, "\n", "")
+	normalizedWanted := strings.ReplaceAll(wanted, "\n", "")
+	if normalizedGot != normalizedWanted {
+		t.Errorf("build graphivz graph failed. \nwanted \n%s\nand got \n%s\n", wanted, got)
+	}
+}
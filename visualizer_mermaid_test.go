@@ -2,6 +2,7 @@ package fsm
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -21,10 +22,15 @@ func Test_MermaidStateDiagram(t *testing.T) {
 	}
 	wanted := `
 stateDiagram-v2
-    [*] --> closed
-    closed --> opened: open
-    intermediate --> closed: partial-close
-    opened --> closed: close
+    state "closed" as s_closed
+    state "intermediate" as s_intermediate
+    state "opened" as s_opened
+    [*] --> s_closed
+    s_closed --> s_opened: open
+    s_intermediate --> s_closed: partial-close
+    s_opened --> s_closed: close
+    classDef current fill:#00AA00
+    class s_closed current
 `
 	normalizedGot := strings.ReplaceAll(got, "\n", "")
 	normalizedWanted := strings.ReplaceAll(wanted, "\n", "")
@@ -55,10 +61,15 @@ func Test_MermaidStateDiagram_CustomName(t *testing.T) {
 title: Lamp FSM
 ---
 stateDiagram-v2
-    [*] --> closed
-    closed --> opened: open
-    intermediate --> closed: partial-close
-    opened --> closed: close
+    state "closed" as s_closed
+    state "intermediate" as s_intermediate
+    state "opened" as s_opened
+    [*] --> s_closed
+    s_closed --> s_opened: open
+    s_intermediate --> s_closed: partial-close
+    s_opened --> s_closed: close
+    classDef current fill:#00AA00
+    class s_closed current
 `
 	normalizedGot := strings.ReplaceAll(got, "\n", "")
 	normalizedWanted := strings.ReplaceAll(wanted, "\n", "")
@@ -86,17 +97,17 @@ func Test_MermaidFlowChart(t *testing.T) {
 	}
 	wanted := `
 graph LR
-    id0[closed]
-    id1[intermediate]
-    id2[opened]
+    s_closed[closed]
+    s_intermediate[intermediate]
+    s_opened[opened]
 
-    id0 --> |open| id2
-    id0 --> |partial-open| id1
-    id1 --> |partial-close| id0
-    id1 --> |partial-open| id2
-    id2 --> |close| id0
+    s_closed --> |open| s_opened
+    s_closed --> |partial-open| s_intermediate
+    s_intermediate --> |partial-close| s_closed
+    s_intermediate --> |partial-open| s_opened
+    s_opened --> |close| s_closed
 
-    style id0 fill:#00AA00
+    style s_closed fill:#00AA00
 `
 	normalizedGot := strings.ReplaceAll(got, "\n", "")
 	normalizedWanted := strings.ReplaceAll(wanted, "\n", "")
@@ -129,17 +140,17 @@ func Test_MermaidFlowChart_CustomName(t *testing.T) {
 title: Lamp FSM
 ---
 graph LR
-    id0[closed]
-    id1[intermediate]
-    id2[opened]
+    s_closed[closed]
+    s_intermediate[intermediate]
+    s_opened[opened]
 
-    id0 --> |open| id2
-    id0 --> |partial-open| id1
-    id1 --> |partial-close| id0
-    id1 --> |partial-open| id2
-    id2 --> |close| id0
+    s_closed --> |open| s_opened
+    s_closed --> |partial-open| s_intermediate
+    s_intermediate --> |partial-close| s_closed
+    s_intermediate --> |partial-open| s_opened
+    s_opened --> |close| s_closed
 
-    style id0 fill:#00AA00
+    style s_closed fill:#00AA00
 `
 	normalizedGot := strings.ReplaceAll(got, "\n", "")
 	normalizedWanted := strings.ReplaceAll(wanted, "\n", "")
@@ -149,3 +160,52 @@ graph LR
 		fmt.Println([]byte(normalizedWanted))
 	}
 }
+
+func Test_MermaidStateDiagram_UndeclaredCurrent(t *testing.T) {
+	fsmUnderTest := NewSafeFsm[LampEvent, LampStatus](
+		LampStatus_Intermediate,
+		NewTransition([]Transform[LampEvent, LampStatus]{
+			{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+			{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+		}),
+	)
+	got, err := VisualizeMermaid[LampEvent, LampStatus](StateDiagram, fsmUnderTest)
+	if err != nil {
+		t.Errorf("got error for visualizing with type MERMAID: %s", err)
+	}
+	wanted := `stateDiagram-v2
+    state "closed" as s_closed
+    state "intermediate" as s_intermediate
+    state "opened" as s_opened
+    [*] --> s_intermediate
+    s_closed --> s_opened: open
+    s_opened --> s_closed: close
+    classDef current fill:#00AA00
+    class s_intermediate current
+`
+	if got != wanted {
+		t.Errorf("build mermaid graph failed. \nwanted \n%s\nand got \n%s\n", wanted, got)
+	}
+}
+
+func Test_intoStateIds(t *testing.T) {
+	tests := []struct {
+		states []string
+		wanted map[string]string
+	}{
+		{
+			states: []string{"a", "a b", "a-b", "end"},
+			wanted: map[string]string{"a": "s_a", "a b": "s_a_b", "a-b": "s_a_b_2", "end": "s_end"},
+		},
+		{
+			// the ids of the other states are kept when a state is added.
+			states: []string{"0", "a", "a b", "a-b", "end"},
+			wanted: map[string]string{"0": "s_0", "a": "s_a", "a b": "s_a_b", "a-b": "s_a_b_2", "end": "s_end"},
+		},
+	}
+	for _, tt := range tests {
+		if got := intoStateIds(tt.states); !reflect.DeepEqual(got, tt.wanted) {
+			t.Errorf("intoStateIds(%q) = %v, wanted %v", tt.states, got, tt.wanted)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package fsm
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
+)
+
+const highlightingColor = "#00AA00"
+
+// Direction the layout direction of the visualization
+type Direction string
+
+const (
+	// LeftToRight lays out the visualization from left to right
+	LeftToRight Direction = "LR"
+	// TopToBottom lays out the visualization from top to bottom
+	TopToBottom Direction = "TB"
+)
+
+// StateShape the shape of the state in the visualization
+type StateShape string
+
+const (
+	// ShapeDefault the default shape of the output format
+	ShapeDefault StateShape = ""
+	// ShapeRect the rectangle shape
+	ShapeRect StateShape = "rect"
+	// ShapeRounded the rectangle shape with rounded corners
+	ShapeRounded StateShape = "rounded"
+	// ShapeCircle the circle shape
+	ShapeCircle StateShape = "circle"
+	// ShapeDoubleCircle the double circle shape
+	ShapeDoubleCircle StateShape = "doublecircle"
+)
+
+// VisualizeOption the option of the visualization
+type VisualizeOption func(*visualizeOptions)
+
+type visualizeOptions struct {
+	direction            Direction
+	highlightColor       string
+	initialShape         StateShape
+	terminalShape        StateShape
+	initialState         any
+	highlightAvailEvents bool
+	currentMarker        bool
+}
+
+// newVisualizeOptions returns the options to visualize the states of type S,
+// it returns an error if the initial state is not type S.
+func newVisualizeOptions[S constraints.Ordered](opts ...VisualizeOption) (*visualizeOptions, error) {
+	o := &visualizeOptions{
+		highlightColor: highlightingColor,
+		currentMarker:  true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.initialState != nil {
+		if _, ok := o.initialState.(S); !ok {
+			var state S
+			return nil, fmt.Errorf("fsm: initial state %v is type %T, not the state type %T", o.initialState, o.initialState, state)
+		}
+	}
+	return o, nil
+}
+
+// WithDirection sets the layout direction, default is the one of the output format.
+// It is not supported by SVG, which is always laid out from left to right.
+func WithDirection(d Direction) VisualizeOption {
+	return func(o *visualizeOptions) {
+		o.direction = d
+	}
+}
+
+// WithHighlightColor sets the color in hex form to highlight the current state and the available events,
+// default is #00AA00.
+func WithHighlightColor(color string) VisualizeOption {
+	return func(o *visualizeOptions) {
+		o.highlightColor = color
+	}
+}
+
+// WithInitialShape sets the shape of the initial states.
+// The shapes are supported by Graphviz and the Mermaid flow chart,
+// the Mermaid state diagram marks the initial states with a transition from the start point instead.
+func WithInitialShape(shape StateShape) VisualizeOption {
+	return func(o *visualizeOptions) {
+		o.initialShape = shape
+	}
+}
+
+// WithTerminalShape sets the shape of the terminal states, which have no outgoing event.
+// The shapes are supported by Graphviz and the Mermaid flow chart,
+// the Mermaid state diagram marks the terminal states with a transition to the end point instead.
+func WithTerminalShape(shape StateShape) VisualizeOption {
+	return func(o *visualizeOptions) {
+		o.terminalShape = shape
+	}
+}
+
+// WithInitialState sets the initial state, default are the states without incoming event from other states.
+// The state must be the state type of the Fsm, otherwise the visualization returns an error.
+func WithInitialState[S constraints.Ordered](state S) VisualizeOption {
+	return func(o *visualizeOptions) {
+		o.initialState = state
+	}
+}
+
+// WithHighlightAvailEvents sets whether to highlight the available events of the current state, default is false.
+// The Mermaid state diagram can not style the transitions, it outlines the destination states of them instead.
+func WithHighlightAvailEvents(enabled bool) VisualizeOption {
+	return func(o *visualizeOptions) {
+		o.highlightAvailEvents = enabled
+	}
+}
+
+// WithCurrentMarker sets whether to mark the current state, default is true.
+func WithCurrentMarker(enabled bool) VisualizeOption {
+	return func(o *visualizeOptions) {
+		o.currentMarker = enabled
+	}
+}
+
+type stateKind int
+
+const (
+	stateKindNormal stateKind = iota
+	stateKindInitial
+	stateKindTerminal
+)
+
+// shape returns the shape of the kind of state.
+func (o *visualizeOptions) shape(kind stateKind) StateShape {
+	switch kind {
+	case stateKindInitial:
+		return o.initialShape
+	case stateKindTerminal:
+		return o.terminalShape
+	default:
+		return ShapeDefault
+	}
+}
+
+// intoStateKinds returns the initial and terminal states which have the shape to set, the initial takes precedence.
+func intoStateKinds[E constraints.Ordered, S constraints.Ordered](o *visualizeOptions, sortedStates []S, sortedEdges []Edge[E, S]) map[S]stateKind {
+	kinds := make(map[S]stateKind)
+	if o.initialShape == ShapeDefault && o.terminalShape == ShapeDefault {
+		return kinds
+	}
+	hasIncoming := make(map[S]bool)
+	hasOutgoing := make(map[S]bool)
+	for _, edge := range sortedEdges {
+		hasOutgoing[edge.Src] = true
+		if edge.Src != edge.Dst {
+			hasIncoming[edge.Dst] = true
+		}
+	}
+	for _, state := range sortedStates {
+		var initial bool
+		if o.initialState != nil {
+			initial = any(state) == o.initialState
+		} else {
+			initial = !hasIncoming[state]
+		}
+		switch {
+		case initial && o.initialShape != ShapeDefault:
+			kinds[state] = stateKindInitial
+		case !hasOutgoing[state] && o.terminalShape != ShapeDefault:
+			kinds[state] = stateKindTerminal
+		}
+	}
+	return kinds
+}
+
+// intoSortedStates returns the sorted states of the transition,
+// with the current state if it is marked but not used by any transform.
+func intoSortedStates[E constraints.Ordered, S constraints.Ordered](o *visualizeOptions, fsm Visualizer[E, S]) []S {
+	sortedStates := fsm.SortedStates()
+	if !o.currentMarker {
+		return sortedStates
+	}
+	current := fsm.Current()
+	i, found := slices.BinarySearch(sortedStates, current)
+	if found {
+		return sortedStates
+	}
+	return slices.Insert(slices.Clone(sortedStates), i, current)
+}
+
+// isAvailEdge reports whether the edge is an available event of the current state to highlight.
+func isAvailEdge[E constraints.Ordered, S constraints.Ordered](o *visualizeOptions, fsm Visualizer[E, S], edge Edge[E, S]) bool {
+	return o.highlightAvailEvents && edge.Src == fsm.Current()
+}
@@ -0,0 +1,206 @@
+package fsm
+
+import (
+	"testing"
+)
+
+func newTestVisualizeOptionsFsm() IFsm[LampEvent, LampStatus] {
+	return NewFsm[LampEvent, LampStatus](
+		LampStatus_Closed,
+		NewTransition([]Transform[LampEvent, LampStatus]{
+			{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+			{Event: LampEvent_PartialOpen, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Intermediate},
+			{Event: LampEvent_PartialOpen, Src: []LampStatus{LampStatus_Intermediate}, Dst: LampStatus_Opened},
+		}),
+	)
+}
+
+func Test_VisualizeWithOptions(t *testing.T) {
+	opts := []VisualizeOption{
+		WithDirection(TopToBottom),
+		WithHighlightColor("#FF0000"),
+		WithInitialShape(ShapeCircle),
+		WithTerminalShape(ShapeDoubleCircle),
+		WithHighlightAvailEvents(true),
+	}
+	tests := []struct {
+		name   string
+		t      VisualizeType
+		wanted string
+	}{
+		{
+			name: "graphviz",
+			t:    Graphviz,
+			wanted: `digraph fsm {
+    rankdir=TB
+    s_closed -> s_opened [ label = "open", color = "#FF0000", fontcolor = "#FF0000" ];
+    s_closed -> s_intermediate [ label = "partial-open", color = "#FF0000", fontcolor = "#FF0000" ];
+    s_intermediate -> s_opened [ label = "partial-open" ];
+
+    s_closed [ label = "closed", shape = circle, style = "filled", fillcolor = "#FF0000" ];
+    s_intermediate [ label = "intermediate" ];
+    s_opened [ label = "opened", shape = doublecircle ];
+}
+`,
+		},
+		{
+			name: "mermaid state diagram",
+			t:    MermaidStateDiagram,
+			wanted: `stateDiagram-v2
+    direction TB
+    state "closed" as s_closed
+    state "intermediate" as s_intermediate
+    state "opened" as s_opened
+    [*] --> s_closed
+    s_closed --> s_opened: open
+    s_closed --> s_intermediate: partial-open
+    s_intermediate --> s_opened: partial-open
+    s_opened --> [*]
+    classDef current fill:#FF0000
+    class s_closed current
+    classDef avail stroke:#FF0000
+    class s_opened,s_intermediate avail
+`,
+		},
+		{
+			name: "mermaid flow chart",
+			t:    MermaidFlowChart,
+			wanted: `graph TB
+    s_closed((closed))
+    s_intermediate[intermediate]
+    s_opened(((opened)))
+
+    s_closed --> |open| s_opened
+    s_closed --> |partial-open| s_intermediate
+    s_intermediate --> |partial-open| s_opened
+
+    linkStyle 0 stroke:#FF0000
+    linkStyle 1 stroke:#FF0000
+    style s_closed fill:#FF0000
+`,
+		},
+		{
+			name: "plantuml",
+			t:    PlantUML,
+			wanted: `@startuml
+top to bottom direction
+state "closed" as s_closed #FF0000
+state "intermediate" as s_intermediate
+state "opened" as s_opened
+
+[*] --> s_closed
+s_closed -[#FF0000]-> s_opened : open
+s_closed -[#FF0000]-> s_intermediate : partial-open
+s_intermediate --> s_opened : partial-open
+@enduml
+`,
+		},
+		{
+			name: "d2",
+			t:    D2,
+			wanted: `direction: down
+s_closed: "closed" {
+  style.fill: "#FF0000"
+}
+s_intermediate: "intermediate"
+s_opened: "opened"
+
+s_closed -> s_opened: "open" {
+  style.stroke: "#FF0000"
+}
+s_closed -> s_intermediate: "partial-open" {
+  style.stroke: "#FF0000"
+}
+s_intermediate -> s_opened: "partial-open"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestVisualizeOptionsFsm().VisualizeWithOptions(tt.t, opts...)
+			if err != nil {
+				t.Errorf("got error for visualizing with type %s: %s", tt.t, err)
+			}
+			if got != tt.wanted {
+				t.Errorf("build graph failed. \nwanted \n%s\nand got \n%s\n", tt.wanted, got)
+			}
+		})
+	}
+}
+
+func Test_VisualizeWithOptions_WithoutCurrentMarker(t *testing.T) {
+	opts := []VisualizeOption{
+		WithCurrentMarker(false),
+		WithInitialState(LampStatus_Intermediate),
+		WithInitialShape(ShapeRounded),
+	}
+	tests := []struct {
+		name   string
+		t      VisualizeType
+		wanted string
+	}{
+		{
+			name: "graphviz",
+			t:    Graphviz,
+			wanted: `digraph fsm {
+    s_closed -> s_opened [ label = "open" ];
+    s_closed -> s_intermediate [ label = "partial-open" ];
+    s_intermediate -> s_opened [ label = "partial-open" ];
+
+    s_closed [ label = "closed" ];
+    s_intermediate [ label = "intermediate", shape = box, style = "rounded" ];
+    s_opened [ label = "opened" ];
+}
+`,
+		},
+		{
+			name: "mermaid state diagram",
+			t:    MermaidStateDiagram,
+			wanted: `stateDiagram-v2
+    state "closed" as s_closed
+    state "intermediate" as s_intermediate
+    state "opened" as s_opened
+    [*] --> s_intermediate
+    s_closed --> s_opened: open
+    s_closed --> s_intermediate: partial-open
+    s_intermediate --> s_opened: partial-open
+`,
+		},
+		{
+			name: "mermaid flow chart",
+			t:    MermaidFlowChart,
+			wanted: `graph LR
+    s_closed[closed]
+    s_intermediate(intermediate)
+    s_opened[opened]
+
+    s_closed --> |open| s_opened
+    s_closed --> |partial-open| s_intermediate
+    s_intermediate --> |partial-open| s_opened
+
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestVisualizeOptionsFsm().VisualizeWithOptions(tt.t, opts...)
+			if err != nil {
+				t.Errorf("got error for visualizing with type %s: %s", tt.t, err)
+			}
+			if got != tt.wanted {
+				t.Errorf("build graph failed. \nwanted \n%s\nand got \n%s\n", tt.wanted, got)
+			}
+		})
+	}
+}
+
+func Test_VisualizeWithOptions_InitialStateTypeMismatch(t *testing.T) {
+	for _, typ := range []VisualizeType{Graphviz, MermaidStateDiagram, MermaidFlowChart, PlantUML, D2, SVG} {
+		t.Run(string(typ), func(t *testing.T) {
+			_, err := newTestVisualizeOptionsFsm().VisualizeWithOptions(typ, WithInitialState("intermediate"), WithInitialShape(ShapeRounded))
+			if err == nil {
+				t.Errorf("expected an error for the initial state of type string, but got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/constraints"
+)
+
+// VisualizePlantUML outputs a visualization of a Fsm in PlantUML state diagram format (including highlighting of current state).
+func VisualizePlantUML[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], opts ...VisualizeOption) (string, error) {
+	o, err := newVisualizeOptions[S](opts...)
+	if err != nil {
+		return "", err
+	}
+	v := newVisualizePlantUMLBuilder(fsm, o).
+		writeHeader().
+		writeStates().
+		writeTransitions().
+		writeFooter()
+	if v.Err() != nil {
+		return "", v.Err()
+	}
+	return v.String(), nil
+}
+
+type visualizePlantUMLBuilder[E constraints.Ordered, S constraints.Ordered] struct {
+	fsm          Visualizer[E, S]
+	opts         *visualizeOptions
+	sortedEdges  []Edge[E, S] // we sort the key alphabetically to have a reproducible graph output
+	sortedStates []S
+	statesId     map[S]string
+	buf          strings.Builder
+	err          error
+}
+
+func newVisualizePlantUMLBuilder[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], opts *visualizeOptions) *visualizePlantUMLBuilder[E, S] {
+	sortedStates := intoSortedStates(opts, fsm)
+	return &visualizePlantUMLBuilder[E, S]{
+		fsm:          fsm,
+		opts:         opts,
+		sortedEdges:  sortedEdges[E, S](fsm),
+		sortedStates: sortedStates,
+		statesId:     intoStateIds(sortedStates),
+	}
+}
+
+func (v *visualizePlantUMLBuilder[E, S]) writeHeader() *visualizePlantUMLBuilder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	v.buf.WriteString("@startuml\n")
+	if v.fsm.Name() != "" {
+		v.buf.WriteString(fmt.Sprintf("title %s\n", plantUMLEscape(v.fsm.Name())))
+	}
+	switch v.opts.direction {
+	case LeftToRight:
+		v.buf.WriteString("left to right direction\n")
+	case TopToBottom:
+		v.buf.WriteString("top to bottom direction\n")
+	}
+	return v
+}
+
+func (v *visualizePlantUMLBuilder[E, S]) writeStates() *visualizePlantUMLBuilder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	for _, state := range v.sortedStates {
+		v.buf.WriteString(fmt.Sprintf(`state "%s" as %s`, plantUMLEscape(v.fsm.StateName(state)), v.statesId[state]))
+		if v.opts.currentMarker && state == v.fsm.Current() {
+			v.buf.WriteString(" " + v.opts.highlightColor)
+		}
+		v.buf.WriteString("\n")
+	}
+	v.buf.WriteString("\n")
+	return v
+}
+
+func (v *visualizePlantUMLBuilder[E, S]) writeTransitions() *visualizePlantUMLBuilder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	if v.opts.currentMarker {
+		v.buf.WriteString(fmt.Sprintf("[*] --> %s\n", v.statesId[v.fsm.Current()]))
+	}
+	for _, edge := range v.sortedEdges {
+		arrow := "-->"
+		if isAvailEdge(v.opts, v.fsm, edge) {
+			arrow = fmt.Sprintf("-[%s]->", v.opts.highlightColor)
+		}
+		v.buf.WriteString(fmt.Sprintf("%s %s %s : %s", v.statesId[edge.Src], arrow, v.statesId[edge.Dst], plantUMLEscape(edgeLabel(v.fsm, edge))))
+		v.buf.WriteString("\n")
+	}
+	return v
+}
+
+func (v *visualizePlantUMLBuilder[E, S]) writeFooter() *visualizePlantUMLBuilder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	v.buf.WriteString("@enduml\n")
+	return v
+}
+
+func (v *visualizePlantUMLBuilder[E, S]) Err() error {
+	return v.err
+}
+
+func (v *visualizePlantUMLBuilder[E, S]) String() string {
+	return v.buf.String()
+}
@@ -0,0 +1,94 @@
+package fsm
+
+import (
+	"testing"
+)
+
+func Test_PlantUML(t *testing.T) {
+	fsmUnderTest := NewFsm[LampEvent, LampStatus](
+		LampStatus_Closed,
+		NewTransition([]Transform[LampEvent, LampStatus]{
+			{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+			{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+			{Event: LampEvent_PartialClose, Src: []LampStatus{LampStatus_Intermediate}, Dst: LampStatus_Closed},
+		}),
+	)
+	got, err := fsmUnderTest.Visualize(PlantUML)
+	if err != nil {
+		t.Errorf("got error for visualizing with type PlantUML: %s", err)
+	}
+	wanted := `@startuml
+state "closed" as s_closed #00AA00
+state "intermediate" as s_intermediate
+state "opened" as s_opened
+
+[*] --> s_closed
+s_closed --> s_opened : open
+s_intermediate --> s_closed : partial-close
+s_opened --> s_closed : close
+@enduml
+`
+	if got != wanted {
+		t.Errorf("build plantuml graph failed. \nwanted \n%s\nand got \n%s\n", wanted, got)
+	}
+}
+
+func Test_PlantUML_CustomName(t *testing.T) {
+	fsmUnderTest := NewSafeFsm[LampEvent, LampStatus](
+		LampStatus_Opened,
+		NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+			{Name: formatEvent(LampEvent_Open), Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+			{Name: formatEvent(LampEvent_Close), Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+		}).
+			Name("Lamp FSM").
+			StateNames(map[LampStatus]string{
+				LampStatus_Opened: formatState(LampStatus_Opened),
+				LampStatus_Closed: formatState(LampStatus_Closed),
+			}).
+			Build(),
+	)
+	got, err := VisualizePlantUML[LampEvent, LampStatus](fsmUnderTest)
+	if err != nil {
+		t.Errorf("got error for visualizing with type PlantUML: %s", err)
+	}
+	wanted := `@startuml
+title Lamp FSM
+state ">closed" as s_closed
+state ">opened" as s_opened #00AA00
+
+[*] --> s_opened
+s_closed --> s_opened : <open>
+s_opened --> s_closed : <close>
+@enduml
+`
+	if got != wanted {
+		t.Errorf("build plantuml graph failed. \nwanted \n%s\nand got \n%s\n", wanted, got)
+	}
+}
+
+func Test_PlantUML_UndeclaredCurrent(t *testing.T) {
+	fsmUnderTest := NewSafeFsm[LampEvent, LampStatus](
+		LampStatus_Intermediate,
+		NewTransition([]Transform[LampEvent, LampStatus]{
+			{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+			{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+		}),
+	)
+	got, err := VisualizePlantUML[LampEvent, LampStatus](fsmUnderTest)
+	if err != nil {
+		t.Errorf("got error for visualizing with type PlantUML: %s", err)
+	}
+	wanted := `@startuml
+state "closed" as s_closed
+state "intermediate" as s_intermediate #00AA00
+state "opened" as s_opened
+
+[*] --> s_intermediate
+s_closed --> s_opened : open
+s_opened --> s_closed : close
+@enduml
+`
+	if got != wanted {
+		t.Errorf("build plantuml graph failed. \nwanted \n%s\nand got \n%s\n", wanted, got)
+	}
+}
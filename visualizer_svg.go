@@ -0,0 +1,370 @@
+package fsm
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
+)
+
+// svg layout metrics, in pixel.
+const (
+	svgMargin      = 20.0
+	svgFontSize    = 14.0
+	svgCharWidth   = 8.0 // approximate width of a character with the font size
+	svgNodeHeight  = 36.0
+	svgNodeMinW    = 60.0
+	svgNodePadding = 24.0
+	svgLayerGap    = 80.0
+	svgNodeGap     = 40.0
+	svgTitleHeight = 30.0
+	svgLoopHeight  = 36.0
+	svgBackEdgeGap = 30.0
+)
+
+// VisualizeSVG outputs a visualization of a Fsm in SVG format (including highlighting of current state).
+// It is laid out in layers from the current state by pure Go, without the external Graphviz.
+func VisualizeSVG[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], opts ...VisualizeOption) (string, error) {
+	o, err := newVisualizeOptions[S](opts...)
+	if err != nil {
+		return "", err
+	}
+	v := newVisualizeSVGBuilder(fsm, o).
+		layout().
+		writeHeader().
+		writeEdges().
+		writeNodes().
+		writeFooter()
+	if v.Err() != nil {
+		return "", v.Err()
+	}
+	return v.String(), nil
+}
+
+type svgNode struct {
+	label string
+	layer int
+	order int
+	x, y  float64
+	w, h  float64
+}
+
+type svgEdge struct {
+	label string
+	path  string
+	lx    float64
+	ly    float64
+	avail bool
+}
+
+type visualizeSVGBuilder[E constraints.Ordered, S constraints.Ordered] struct {
+	fsm          Visualizer[E, S]
+	opts         *visualizeOptions
+	sortedEdges  []Edge[E, S] // we sort the key alphabetically to have a reproducible graph output
+	sortedStates []S
+	nodes        map[S]*svgNode
+	edges        []svgEdge
+	width        float64
+	height       float64
+	buf          strings.Builder
+	err          error
+}
+
+func newVisualizeSVGBuilder[E constraints.Ordered, S constraints.Ordered](fsm Visualizer[E, S], opts *visualizeOptions) *visualizeSVGBuilder[E, S] {
+	return &visualizeSVGBuilder[E, S]{
+		fsm:          fsm,
+		opts:         opts,
+		sortedEdges:  sortedEdges[E, S](fsm),
+		sortedStates: fsm.SortedStates(),
+		nodes:        make(map[S]*svgNode),
+	}
+}
+
+// layout assigns the states to layers by the distance from the current state, orders the states in each layer
+// by the barycenter of their predecessors, then places the states and routes the edges.
+func (v *visualizeSVGBuilder[E, S]) layout() *visualizeSVGBuilder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	layers := v.assignLayers()
+	v.orderLayers(layers)
+
+	// place the states, the layers from left to right, the states of a layer from top to bottom.
+	loops := make(map[S]int)
+	maxLoops := 0
+	for _, edge := range v.sortedEdges {
+		if edge.Src == edge.Dst {
+			loops[edge.Src]++
+			if loops[edge.Src] > maxLoops {
+				maxLoops = loops[edge.Src]
+			}
+		}
+	}
+	top := svgMargin
+	if maxLoops > 0 {
+		top += svgLoopHeight*(1+float64(maxLoops-1)*0.6)*0.75 + svgFontSize + 4
+	}
+	if v.fsm.Name() != "" {
+		top += svgTitleHeight
+	}
+	layerHeights := make([]float64, len(layers))
+	maxLayerHeight := 0.0
+	for i, layer := range layers {
+		layerHeights[i] = float64(len(layer))*(svgNodeHeight+svgNodeGap) - svgNodeGap
+		if layerHeights[i] > maxLayerHeight {
+			maxLayerHeight = layerHeights[i]
+		}
+	}
+	layerGap := svgLayerGap
+	for _, edge := range v.sortedEdges {
+		if w := textWidth(edgeLabel(v.fsm, edge)) + svgNodePadding; w > layerGap {
+			layerGap = w
+		}
+	}
+	x := svgMargin
+	for i, layer := range layers {
+		layerWidth := 0.0
+		for _, state := range layer {
+			if w := v.nodes[state].w; w > layerWidth {
+				layerWidth = w
+			}
+		}
+		y := top + (maxLayerHeight-layerHeights[i])/2
+		for _, state := range layer {
+			n := v.nodes[state]
+			n.x = x + (layerWidth-n.w)/2
+			n.y = y
+			y += svgNodeHeight + svgNodeGap
+		}
+		x += layerWidth + layerGap
+	}
+	v.width = x - layerGap + svgMargin
+	bottom := top + maxLayerHeight
+	v.height = v.routeEdges(bottom) + svgMargin
+	return v
+}
+
+// assignLayers assigns the states to layers by the breadth-first distance from the current state,
+// the states which can not be reached are laid out from the first of them in sorted order.
+func (v *visualizeSVGBuilder[E, S]) assignLayers() [][]S {
+	successors := make(map[S][]S)
+	for _, edge := range v.sortedEdges {
+		successors[edge.Src] = append(successors[edge.Src], edge.Dst)
+	}
+	states := v.sortedStates
+	if !slices.Contains(states, v.fsm.Current()) {
+		states = append([]S{v.fsm.Current()}, states...)
+	}
+	layers := make([][]S, 0)
+	visit := func(start S) {
+		queue := []S{start}
+		v.nodes[start] = &svgNode{layer: 0}
+		for len(queue) > 0 {
+			state := queue[0]
+			queue = queue[1:]
+			n := v.nodes[state]
+			n.label = v.fsm.StateName(state)
+			n.w = textWidth(n.label) + svgNodePadding
+			if n.w < svgNodeMinW {
+				n.w = svgNodeMinW
+			}
+			n.h = svgNodeHeight
+			for len(layers) <= n.layer {
+				layers = append(layers, make([]S, 0))
+			}
+			n.order = len(layers[n.layer])
+			layers[n.layer] = append(layers[n.layer], state)
+			for _, next := range successors[state] {
+				if _, ok := v.nodes[next]; !ok {
+					v.nodes[next] = &svgNode{layer: n.layer + 1}
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	visit(v.fsm.Current())
+	for _, state := range states {
+		if _, ok := v.nodes[state]; !ok {
+			visit(state)
+		}
+	}
+	return layers
+}
+
+// orderLayers orders the states of each layer by the barycenter of their predecessors in the previous layer.
+func (v *visualizeSVGBuilder[E, S]) orderLayers(layers [][]S) {
+	predecessors := make(map[S][]S)
+	for _, edge := range v.sortedEdges {
+		if v.nodes[edge.Dst].layer == v.nodes[edge.Src].layer+1 {
+			predecessors[edge.Dst] = append(predecessors[edge.Dst], edge.Src)
+		}
+	}
+	for sweep := 0; sweep < 2; sweep++ {
+		for i := 1; i < len(layers); i++ {
+			barycenter := make(map[S]float64)
+			for _, state := range layers[i] {
+				preds := predecessors[state]
+				if len(preds) == 0 {
+					barycenter[state] = float64(v.nodes[state].order)
+					continue
+				}
+				sum := 0.0
+				for _, pred := range preds {
+					sum += float64(v.nodes[pred].order)
+				}
+				barycenter[state] = sum / float64(len(preds))
+			}
+			slices.SortStableFunc(layers[i], func(a, b S) bool {
+				return barycenter[a] < barycenter[b]
+			})
+			for order, state := range layers[i] {
+				v.nodes[state].order = order
+			}
+		}
+	}
+}
+
+// routeEdges routes the edges, the forward edges go from the right side of the source state to
+// the left side of the destination state, the self loops go above the state, the other edges go below all states.
+// It returns the bottom of the drawing.
+func (v *visualizeSVGBuilder[E, S]) routeEdges(bottom float64) float64 {
+	type pair struct{ src, dst S }
+
+	total := make(map[pair]int)
+	for _, edge := range v.sortedEdges {
+		total[pair{edge.Src, edge.Dst}]++
+	}
+	seen := make(map[pair]int)
+	backEdges := 0
+	maxBottom := bottom
+	for _, edge := range v.sortedEdges {
+		p := pair{edge.Src, edge.Dst}
+		k := seen[p]
+		seen[p]++
+		src, dst := v.nodes[edge.Src], v.nodes[edge.Dst]
+		e := svgEdge{label: edgeLabel(v.fsm, edge), avail: isAvailEdge(v.opts, v.fsm, edge)}
+		switch {
+		case edge.Src == edge.Dst:
+			cx, top := src.x+src.w/2, src.y
+			height := svgLoopHeight * (1 + float64(k)*0.6)
+			e.path = fmt.Sprintf("M %s %s C %s %s %s %s %s %s",
+				svgNum(cx-10), svgNum(top), svgNum(cx-30), svgNum(top-height),
+				svgNum(cx+30), svgNum(top-height), svgNum(cx+10), svgNum(top))
+			e.lx, e.ly = cx, top-height*0.75-4
+		case dst.layer > src.layer:
+			sx, sy := src.x+src.w, src.y+src.h/2
+			ex, ey := dst.x, dst.y+dst.h/2
+			bow := (float64(k) - float64(total[p]-1)/2) * 24
+			dx := (ex - sx) / 2
+			e.path = fmt.Sprintf("M %s %s C %s %s %s %s %s %s",
+				svgNum(sx), svgNum(sy), svgNum(sx+dx), svgNum(sy+bow),
+				svgNum(ex-dx), svgNum(ey+bow), svgNum(ex), svgNum(ey))
+			e.lx, e.ly = (sx+ex)/2, (sy+ey)/2+bow*0.75-6
+		default:
+			backEdges++
+			sx, sy := src.x+src.w/2, src.y+src.h
+			ex, ey := dst.x+dst.w/2, dst.y+dst.h
+			depth := bottom + svgBackEdgeGap*float64(backEdges)
+			e.path = fmt.Sprintf("M %s %s C %s %s %s %s %s %s",
+				svgNum(sx), svgNum(sy), svgNum(sx), svgNum(depth),
+				svgNum(ex), svgNum(depth), svgNum(ex), svgNum(ey))
+			// the middle point of the bezier curve.
+			e.lx, e.ly = (sx+ex)/2, (sy+ey)/8+depth*3/4-4
+			if depth > maxBottom {
+				maxBottom = depth
+			}
+		}
+		v.edges = append(v.edges, e)
+	}
+	return maxBottom
+}
+
+func (v *visualizeSVGBuilder[E, S]) writeHeader() *visualizeSVGBuilder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	v.buf.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-family="sans-serif" font-size="%s">`,
+		svgNum(v.width), svgNum(v.height), svgNum(v.width), svgNum(v.height), svgNum(svgFontSize)))
+	v.buf.WriteString("\n")
+	v.buf.WriteString(`  <defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z"/></marker>`)
+	if v.opts.highlightAvailEvents {
+		v.buf.WriteString(fmt.Sprintf(`<marker id="arrow-avail" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="%s"/></marker>`,
+			html.EscapeString(v.opts.highlightColor)))
+	}
+	v.buf.WriteString("</defs>\n")
+	if v.fsm.Name() != "" {
+		v.buf.WriteString(fmt.Sprintf(`  <text x="%s" y="%s" text-anchor="middle" font-weight="bold">%s</text>`,
+			svgNum(v.width/2), svgNum(svgMargin+svgFontSize), html.EscapeString(v.fsm.Name())))
+		v.buf.WriteString("\n")
+	}
+	return v
+}
+
+func (v *visualizeSVGBuilder[E, S]) writeEdges() *visualizeSVGBuilder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	for _, e := range v.edges {
+		if e.avail {
+			v.buf.WriteString(fmt.Sprintf(`  <path d="%s" fill="none" stroke="%s" marker-end="url(#arrow-avail)"/>`, e.path, html.EscapeString(v.opts.highlightColor)))
+		} else {
+			v.buf.WriteString(fmt.Sprintf(`  <path d="%s" fill="none" stroke="black" marker-end="url(#arrow)"/>`, e.path))
+		}
+		v.buf.WriteString("\n")
+		v.buf.WriteString(fmt.Sprintf(`  <text x="%s" y="%s" text-anchor="middle" stroke="white" stroke-width="3" paint-order="stroke">%s</text>`,
+			svgNum(e.lx), svgNum(e.ly), html.EscapeString(e.label)))
+		v.buf.WriteString("\n")
+	}
+	return v
+}
+
+func (v *visualizeSVGBuilder[E, S]) writeNodes() *visualizeSVGBuilder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	for _, state := range v.sortedStates {
+		n := v.nodes[state]
+		fill := "white"
+		if v.opts.currentMarker && state == v.fsm.Current() {
+			fill = html.EscapeString(v.opts.highlightColor)
+		}
+		v.buf.WriteString(fmt.Sprintf(`  <rect x="%s" y="%s" width="%s" height="%s" rx="8" fill="%s" stroke="black"/>`,
+			svgNum(n.x), svgNum(n.y), svgNum(n.w), svgNum(n.h), fill))
+		v.buf.WriteString("\n")
+		v.buf.WriteString(fmt.Sprintf(`  <text x="%s" y="%s" text-anchor="middle" dominant-baseline="central">%s</text>`,
+			svgNum(n.x+n.w/2), svgNum(n.y+n.h/2), html.EscapeString(n.label)))
+		v.buf.WriteString("\n")
+	}
+	return v
+}
+
+func (v *visualizeSVGBuilder[E, S]) writeFooter() *visualizeSVGBuilder[E, S] {
+	if v.err != nil {
+		return v
+	}
+	v.buf.WriteString("</svg>\n")
+	return v
+}
+
+func (v *visualizeSVGBuilder[E, S]) Err() error {
+	return v.err
+}
+
+func (v *visualizeSVGBuilder[E, S]) String() string {
+	return v.buf.String()
+}
+
+// textWidth returns the approximate width of the text.
+func textWidth(s string) float64 {
+	return float64(utf8.RuneCountInString(s)) * svgCharWidth
+}
+
+// svgNum formats the number with at most one decimal.
+func svgNum(f float64) string {
+	return strconv.FormatFloat(math.Round(f*10)/10, 'f', -1, 64)
+}
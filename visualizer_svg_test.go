@@ -0,0 +1,96 @@
+package fsm
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_SVG(t *testing.T) {
+	fsmUnderTest := NewFsm[LampEvent, LampStatus](
+		LampStatus_Opened,
+		NewTransitionBuilder([]Transform[LampEvent, LampStatus]{
+			{Event: LampEvent_Open, Src: []LampStatus{LampStatus_Closed}, Dst: LampStatus_Opened},
+			{Event: LampEvent_Close, Src: []LampStatus{LampStatus_Opened}, Dst: LampStatus_Closed},
+			{Event: LampEvent_PartialClose, Src: []LampStatus{LampStatus_Intermediate}, Dst: LampStatus_Closed},
+		}).
+			Name("Lamp FSM").
+			Build(),
+	)
+	got, err := fsmUnderTest.Visualize(SVG)
+	if err != nil {
+		t.Errorf("got error for visualizing with type SVG: %s", err)
+	}
+	wanted := `<svg xmlns="http://www.w3.org/2000/svg" width="360" height="212" viewBox="0 0 360 212" font-family="sans-serif" font-size="14">
+  <defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z"/></marker></defs>
+  <text x="180" y="34" text-anchor="middle" font-weight="bold">Lamp FSM</text>
+  <path d="M 304 124 C 304 192 80 192 80 86" fill="none" stroke="black" marker-end="url(#arrow)"/>
+  <text x="192" y="166.3" text-anchor="middle" stroke="white" stroke-width="3" paint-order="stroke">open</text>
+  <path d="M 140 144 C 204 144 204 106 268 106" fill="none" stroke="black" marker-end="url(#arrow)"/>
+  <text x="204" y="119" text-anchor="middle" stroke="white" stroke-width="3" paint-order="stroke">partial-close</text>
+  <path d="M 116 68 C 192 68 192 106 268 106" fill="none" stroke="black" marker-end="url(#arrow)"/>
+  <text x="192" y="81" text-anchor="middle" stroke="white" stroke-width="3" paint-order="stroke">close</text>
+  <rect x="268" y="88" width="72" height="36" rx="8" fill="white" stroke="black"/>
+  <text x="304" y="106" text-anchor="middle" dominant-baseline="central">closed</text>
+  <rect x="20" y="126" width="120" height="36" rx="8" fill="white" stroke="black"/>
+  <text x="80" y="144" text-anchor="middle" dominant-baseline="central">intermediate</text>
+  <rect x="44" y="50" width="72" height="36" rx="8" fill="#00AA00" stroke="black"/>
+  <text x="80" y="68" text-anchor="middle" dominant-baseline="central">opened</text>
+</svg>
+`
+	if got != wanted {
+		t.Errorf("build svg graph failed. \nwanted \n%s\nand got \n%s\n", wanted, got)
+	}
+}
+
+func Test_SVG_SelfLoop(t *testing.T) {
+	fsmUnderTest := NewFsm[string, string](
+		"idle",
+		NewTransition([]Transform[string, string]{
+			{Event: "scan", Src: []string{"idle"}, Dst: "scanning"},
+			{Event: "working", Src: []string{"scanning"}, Dst: "scanning"},
+			{Event: "situation", Src: []string{"scanning"}, Dst: "scanning"},
+			{Event: "situation", Src: []string{"idle"}, Dst: "idle"},
+			{Event: "finish", Src: []string{"scanning"}, Dst: "idle"},
+		}),
+	)
+	got, err := VisualizeSVG[string, string](fsmUnderTest)
+	if err != nil {
+		t.Errorf("got error for visualizing with type SVG: %s", err)
+	}
+	wanted := `<svg xmlns="http://www.w3.org/2000/svg" width="284" height="167.2" viewBox="0 0 284 167.2" font-family="sans-serif" font-size="14">
+  <defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z"/></marker></defs>
+  <path d="M 80 99.2 C 128 99.2 128 99.2 176 99.2" fill="none" stroke="black" marker-end="url(#arrow)"/>
+  <text x="128" y="93.2" text-anchor="middle" stroke="white" stroke-width="3" paint-order="stroke">scan</text>
+  <path d="M 40 81.2 C 20 45.2 80 45.2 60 81.2" fill="none" stroke="black" marker-end="url(#arrow)"/>
+  <text x="50" y="50.2" text-anchor="middle" stroke="white" stroke-width="3" paint-order="stroke">situation</text>
+  <path d="M 220 117.2 C 220 147.2 50 147.2 50 117.2" fill="none" stroke="black" marker-end="url(#arrow)"/>
+  <text x="135" y="135.7" text-anchor="middle" stroke="white" stroke-width="3" paint-order="stroke">finish</text>
+  <path d="M 210 81.2 C 190 45.2 250 45.2 230 81.2" fill="none" stroke="black" marker-end="url(#arrow)"/>
+  <text x="220" y="50.2" text-anchor="middle" stroke="white" stroke-width="3" paint-order="stroke">situation</text>
+  <path d="M 210 81.2 C 190 23.6 250 23.6 230 81.2" fill="none" stroke="black" marker-end="url(#arrow)"/>
+  <text x="220" y="34" text-anchor="middle" stroke="white" stroke-width="3" paint-order="stroke">working</text>
+  <rect x="20" y="81.2" width="60" height="36" rx="8" fill="#00AA00" stroke="black"/>
+  <text x="50" y="99.2" text-anchor="middle" dominant-baseline="central">idle</text>
+  <rect x="176" y="81.2" width="88" height="36" rx="8" fill="white" stroke="black"/>
+  <text x="220" y="99.2" text-anchor="middle" dominant-baseline="central">scanning</text>
+</svg>
+`
+	if got != wanted {
+		t.Errorf("build svg graph failed. \nwanted \n%s\nand got \n%s\n", wanted, got)
+	}
+
+	// the output must be well-formed xml.
+	decoder := xml.NewDecoder(strings.NewReader(got))
+	for {
+		_, err := decoder.Token()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("svg is not well-formed: %s", err)
+			}
+			break
+		}
+	}
+}